@@ -3,6 +3,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -147,39 +148,43 @@ func main() {
 func run(styhClientId, environment, password, secretKey, tempDirectory, username, configFileFQN string) {
 
 	var (
-		accountId   string
-		clientPtr   src.NCClient
-		errorInfo   pi.ErrorInfo
-		reply       []byte
-		replyData   interface{}
-		requestData interface{}
-		systemId    string
-		teamId      string
-		tokenId     string
+		accountId string
+		clientPtr src.NCClient
+		errorInfo pi.ErrorInfo
+		reply     []byte
+		replyData interface{}
+		systemId  string
+		teamId    string
+		tokenId   string
 	)
 
 	// The following is all the code the developer needs to use NATS Connect.
 
 	// Connect to the NATS Connect service.
-	if clientPtr, errorInfo = src.NewNCClient(
-		styhClientId,
-		environment,
-		password,
-		secretKey,
-		tempDirectory,
-		username,
-		configFileFQN,
+	if clientPtr, errorInfo = src.NewNCClientWithOptions(
+		src.WithCredentials(
+			src.Credentials{
+				Password:     password,
+				SecretKey:    secretKey,
+				STYHClientId: styhClientId,
+				Username:     username,
+			},
+		),
+		src.WithEnvironment(environment),
+		src.WithTempDir(tempDirectory),
+		src.WithConfigFile(configFileFQN),
 	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		flaggy.ShowHelpAndExit("")
 	}
 
 	// Sample call to Synadia Cloud List Teams
-	requestData = ncs.ListTeamsRequest{
-		SaaSKey: SYNADIA_CLOUD_TOKEN,
-		BaseURL: SYNADIA_CLOUD_BASE_URL,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaListTeams(requestData); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaListTeams(
+		ncs.ListTeamsRequest{
+			SaaSKey: SYNADIA_CLOUD_TOKEN,
+			BaseURL: SYNADIA_CLOUD_BASE_URL,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -192,12 +197,13 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 	}
 
 	// Sample call to Synadia Cloud Get Team
-	requestData = ncs.GetTeamRequest{
-		SaaSKey: SYNADIA_CLOUD_TOKEN,
-		BaseURL: SYNADIA_CLOUD_BASE_URL,
-		TeamId:  teamId,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaGetTeam(requestData); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaGetTeam(
+		ncs.GetTeamRequest{
+			SaaSKey: SYNADIA_CLOUD_TOKEN,
+			BaseURL: SYNADIA_CLOUD_BASE_URL,
+			TeamId:  teamId,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -209,12 +215,13 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 	}
 
 	// Sample call to Synadia Cloud Get Team Limits
-	requestData = ncs.GetTeamLimitsRequest{
-		SaaSKey: SYNADIA_CLOUD_TOKEN,
-		BaseURL: SYNADIA_CLOUD_BASE_URL,
-		TeamId:  teamId,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaGetTeamLimits(requestData); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaGetTeamLimits(
+		ncs.GetTeamLimitsRequest{
+			SaaSKey: SYNADIA_CLOUD_TOKEN,
+			BaseURL: SYNADIA_CLOUD_BASE_URL,
+			TeamId:  teamId,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -226,12 +233,13 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 	}
 
 	// Sample call to Synadia Cloud List Information App Users Team
-	requestData = ncs.ListInfoAppUserTeamRequest{
-		SaaSKey: SYNADIA_CLOUD_TOKEN,
-		BaseURL: SYNADIA_CLOUD_BASE_URL,
-		TeamId:  teamId,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaListInfoAppUsersTeam(requestData); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaListInfoAppUsersTeam(
+		ncs.ListInfoAppUserTeamRequest{
+			SaaSKey: SYNADIA_CLOUD_TOKEN,
+			BaseURL: SYNADIA_CLOUD_BASE_URL,
+			TeamId:  teamId,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -243,12 +251,13 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 	}
 
 	// Sample call to Synadia Cloud List Personal Access Tokens
-	requestData = ncs.ListPersonalAccessTokensRequest{
-		SaaSKey: SYNADIA_CLOUD_TOKEN,
-		BaseURL: SYNADIA_CLOUD_BASE_URL,
-		TeamId:  teamId,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaListPersonalAccessTokens(requestData); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaListPersonalAccessTokens(
+		ncs.ListPersonalAccessTokensRequest{
+			SaaSKey: SYNADIA_CLOUD_TOKEN,
+			BaseURL: SYNADIA_CLOUD_BASE_URL,
+			TeamId:  teamId,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -261,12 +270,13 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 	}
 
 	// Sample call to Synadia Cloud List Team Server Accounts
-	requestData = ncs.ListTeamServerAccountsRequest{
-		SaaSKey: SYNADIA_CLOUD_TOKEN,
-		BaseURL: SYNADIA_CLOUD_BASE_URL,
-		TeamId:  teamId,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaListTeamServerAccounts(requestData); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaListTeamServerAccounts(
+		ncs.ListTeamServerAccountsRequest{
+			SaaSKey: SYNADIA_CLOUD_TOKEN,
+			BaseURL: SYNADIA_CLOUD_BASE_URL,
+			TeamId:  teamId,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -278,12 +288,13 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 	}
 
 	// Sample call to Synadia Cloud List Systems
-	requestData = ncs.ListSystemsRequest{
-		SaaSKey: SYNADIA_CLOUD_TOKEN,
-		BaseURL: SYNADIA_CLOUD_BASE_URL,
-		TeamId:  teamId,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaListSystems(requestData); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaListSystems(
+		ncs.ListSystemsRequest{
+			SaaSKey: SYNADIA_CLOUD_TOKEN,
+			BaseURL: SYNADIA_CLOUD_BASE_URL,
+			TeamId:  teamId,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -296,12 +307,13 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 	}
 
 	// Sample call to Synadia Cloud Get System
-	requestData = ncs.GetSystemRequest{
-		SaaSKey:  SYNADIA_CLOUD_TOKEN,
-		BaseURL:  SYNADIA_CLOUD_BASE_URL,
-		SystemId: systemId,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaGetSystem(requestData); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaGetSystem(
+		ncs.GetSystemRequest{
+			SaaSKey:  SYNADIA_CLOUD_TOKEN,
+			BaseURL:  SYNADIA_CLOUD_BASE_URL,
+			SystemId: systemId,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -313,12 +325,13 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 	}
 
 	// Sample call to Synadia Cloud Get System Limits
-	requestData = ncs.GetSystemLimitsRequest{
-		SaaSKey:  SYNADIA_CLOUD_TOKEN,
-		BaseURL:  SYNADIA_CLOUD_BASE_URL,
-		SystemId: systemId,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaGetSystemLimits(requestData); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaGetSystemLimits(
+		ncs.GetSystemLimitsRequest{
+			SaaSKey:  SYNADIA_CLOUD_TOKEN,
+			BaseURL:  SYNADIA_CLOUD_BASE_URL,
+			SystemId: systemId,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -330,12 +343,13 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 	}
 
 	// Sample call to Synadia Cloud List Team Server Accounts
-	requestData = ncs.ListSystemAccountInfoRequest{
-		SaaSKey:  SYNADIA_CLOUD_TOKEN,
-		BaseURL:  SYNADIA_CLOUD_BASE_URL,
-		SystemId: systemId,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaListSystemAccountInfo(requestData); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaListSystemAccountInfo(
+		ncs.ListSystemAccountInfoRequest{
+			SaaSKey:  SYNADIA_CLOUD_TOKEN,
+			BaseURL:  SYNADIA_CLOUD_BASE_URL,
+			SystemId: systemId,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -347,12 +361,13 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 	}
 
 	// Sample call to Synadia Cloud List Accounts
-	requestData = ncs.ListAccountsRequest{
-		SaaSKey:  SYNADIA_CLOUD_TOKEN,
-		BaseURL:  SYNADIA_CLOUD_BASE_URL,
-		SystemId: systemId,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaListAccounts(requestData.(ncs.ListAccountsRequest)); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaListAccounts(
+		ncs.ListAccountsRequest{
+			SaaSKey:  SYNADIA_CLOUD_TOKEN,
+			BaseURL:  SYNADIA_CLOUD_BASE_URL,
+			SystemId: systemId,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -365,12 +380,13 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 	}
 
 	// Sample call to Synadia Cloud List System Server Info
-	requestData = ncs.ListSystemServerInfoRequest{
-		SaaSKey:  SYNADIA_CLOUD_TOKEN,
-		BaseURL:  SYNADIA_CLOUD_BASE_URL,
-		SystemId: systemId,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaListSystemServerInfo(requestData); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaListSystemServerInfo(
+		ncs.ListSystemServerInfoRequest{
+			SaaSKey:  SYNADIA_CLOUD_TOKEN,
+			BaseURL:  SYNADIA_CLOUD_BASE_URL,
+			SystemId: systemId,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -382,11 +398,12 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 	}
 
 	// Sample call to Synadia Cloud Get Version
-	requestData = ncs.GetVersionRequest{
-		SaaSKey: SYNADIA_CLOUD_TOKEN,
-		BaseURL: SYNADIA_CLOUD_BASE_URL,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaGetVersion(requestData); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaGetVersion(
+		ncs.GetVersionRequest{
+			SaaSKey: SYNADIA_CLOUD_TOKEN,
+			BaseURL: SYNADIA_CLOUD_BASE_URL,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -398,12 +415,13 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 	}
 
 	// Sample call to Synadia Cloud List NATS Users
-	requestData = ncs.ListNATSUsersRequest{
-		SaaSKey:   SYNADIA_CLOUD_TOKEN,
-		BaseURL:   SYNADIA_CLOUD_BASE_URL,
-		AccountId: accountId,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaListNATSUsers(requestData); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaListNATSUsers(
+		ncs.ListNATSUsersRequest{
+			SaaSKey:   SYNADIA_CLOUD_TOKEN,
+			BaseURL:   SYNADIA_CLOUD_BASE_URL,
+			AccountId: accountId,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -415,12 +433,13 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 	}
 
 	// Sample call to Synadia Cloud Get Personal Access Token
-	requestData = ncs.GetPersonalAccessTokenRequest{
-		SaaSKey: SYNADIA_CLOUD_TOKEN,
-		BaseURL: SYNADIA_CLOUD_BASE_URL,
-		TokenId: tokenId,
-	}
-	if replyData, errorInfo = clientPtr.SynaidaGetPersonalAccessToken(requestData); errorInfo.Error != nil {
+	if replyData, errorInfo = clientPtr.SynaidaGetPersonalAccessToken(
+		ncs.GetPersonalAccessTokenRequest{
+			SaaSKey: SYNADIA_CLOUD_TOKEN,
+			BaseURL: SYNADIA_CLOUD_BASE_URL,
+			TokenId: tokenId,
+		},
+	); errorInfo.Error != nil {
 		pi.PrintErrorInfo(errorInfo)
 		log.Fatalln()
 	} else {
@@ -431,4 +450,9 @@ func run(styhClientId, environment, password, secretKey, tempDirectory, username
 		fmt.Println(string(s))
 	}
 
+	// Disconnect from the NATS Connect service and remove the temporary files.
+	if errorInfo = clientPtr.Close(context.Background()); errorInfo.Error != nil {
+		pi.PrintErrorInfo(errorInfo)
+	}
+
 }
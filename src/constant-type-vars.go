@@ -0,0 +1,87 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"errors"
+)
+
+//goland:noinspection ALL
+const (
+	// Messages
+	BATCH_STOPPED             = "The request was not sent because an earlier request in the batch failed."
+	CIRCUIT_IS_OPEN           = "The circuit breaker is open, the request was not sent."
+	CLIENT_CLOSED             = "The client is closed, the request was not sent."
+	CLIENT_RATE_LIMITED       = "The client rate limit was reached, the request was not sent."
+	ENDPOINT_NOT_REGISTERED   = "The endpoint is not registered with a subject. Use one of the declared endpoints."
+	NOT_FOUND                 = "The requested resource was not found."
+	RATE_LIMITED              = "The upstream service rate limited the request."
+	RATE_LIMITER_OFF          = "The rate limiter is off. Use WithRateLimit to turn it on."
+	REPLY_NOT_ENCRYPTED       = "The reply was not encrypted and encrypted replies are required."
+	REPLY_SIGNATURE_INVALID   = "The reply signature is not valid, the reply was rejected."
+	REPLY_SIGNATURE_MALFORMED = "signature is not base64 encoded"
+	REPLY_SIGNATURE_MISMATCH  = "signature does not match the reply"
+	REPLY_SIGNATURE_MISSING   = "reply is not signed"
+	REPLY_TOO_LARGE           = "The decompressed reply is larger than the client accepts."
+	REQUEST_TIMED_OUT         = "The request timed out waiting for a reply."
+	SERVER_RETURNED_ERROR     = "NATS Connect returned an error."
+	TEAM_LIMITS_FUNC_MISSING  = "The team limits can't be read. Set RateLimitSettings.TeamLimitsFunc to calibrate the rate limit."
+	UNAUTHORIZED              = "NATS Connect or the upstream service rejected the credentials."
+	UNSUPPORTED_COMPRESSION   = "The compression algorithm is not supported."
+	UPSTREAM_FAILED           = "The upstream service failed to process the request."
+	//
+	// Text
+	TXT_BATCH_INDEX             = "Batch Index: "
+	TXT_COMPRESSION             = " Compression: "
+	TXT_CONNECTION_STATUS       = "Connection Status: "
+	TXT_FUTURES                 = "Futures: "
+	TXT_HTTP_STATUS             = " HTTP Status: "
+	TXT_REQUEST_ID              = " Request Id: "
+	TXT_REQUEST_IDS             = " Request Ids: "
+	TXT_REQUEST_TIMEOUT         = " Request Timeout: "
+	TXT_REQUEST_TYPE            = "Request Type: "
+	TXT_RETRY_AFTER             = " Retry After: "
+	TXT_TLS_CERTIFICATE_EXPIRED = "TLS Certificate Expired: "
+	TXT_TOKEN_EXPIRED           = "Token Expired: "
+)
+
+var (
+	ErrBatchStopped           = errors.New(BATCH_STOPPED)
+	ErrCircuitOpen            = errors.New(CIRCUIT_IS_OPEN)
+	ErrClientClosed           = errors.New(CLIENT_CLOSED)
+	ErrClientRateLimited      = errors.New(CLIENT_RATE_LIMITED)
+	ErrEndpointNotRegistered  = errors.New(ENDPOINT_NOT_REGISTERED)
+	ErrNotFound               = errors.New(NOT_FOUND)
+	ErrRateLimiterOff         = errors.New(RATE_LIMITER_OFF)
+	ErrRateLimited            = errors.New(RATE_LIMITED)
+	ErrReplyNotEncrypted      = errors.New(REPLY_NOT_ENCRYPTED)
+	ErrReplySignatureInvalid  = errors.New(REPLY_SIGNATURE_INVALID)
+	ErrReplyTooLarge          = errors.New(REPLY_TOO_LARGE)
+	ErrRequestTimeout         = errors.New(REQUEST_TIMED_OUT)
+	ErrServerError            = errors.New(SERVER_RETURNED_ERROR)
+	ErrTeamLimitsFuncMissing  = errors.New(TEAM_LIMITS_FUNC_MISSING)
+	ErrUnauthorized           = errors.New(UNAUTHORIZED)
+	ErrUnsupportedCompression = errors.New(UNSUPPORTED_COMPRESSION)
+	ErrUpstream               = errors.New(UPSTREAM_FAILED)
+)
@@ -27,10 +27,17 @@ OTHER DEALINGS IN THE SOFTWARE.
 package src
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"log/slog"
+	"os"
 	"strconv"
+	"time"
 
 	awsSSM "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
 
 	ctv "github.com/sty-holdings/constant-type-vars-go/v2024"
 	awss "github.com/sty-holdings/sty-shared/v2024/awsServices"
@@ -43,34 +50,62 @@ import (
 
 //goland:noinspection ALL
 const (
-	PROGRAM_NAME            = "NATS-Connect-go-client"
-	NC_SSM_PARAMETER_PREFIX = "nc"
+	PROGRAM_NAME              = "NATS-Connect-go-client"
+	NC_SSM_PARAMETER_PREFIX   = "nc"
+	TEMPORARY_STAGING_PATTERN = ".staging-*" // Staging directory for replaceTemporaryFiles, inside the temporary directory.
 )
 
 type styhCustomerConfig struct {
 	clientId  string
 	secretKey string
-	tokens    awss.CognitoTokens
 	username  string
 }
 
-// NewNCClient - creates an instance to connect to the NATS Connect server
+// NewNCClient - creates an instance to connect to the NATS Connect server. It is a wrapper for NewNCClientWithOptions.
 //
 //	Customer Messages: None
-//	Errors: ErrRequiredArgumentMissing, returned from validateConfiguration, LoadAWSCustomerSettings, Login, processAWSClientParameters, BuildTemporaryFiles,
-//	BuildTLSTemporaryFiles, BuildInstanceName, GetConnection
-//	Verifications: styhClientId, environment, password, secretKey, tempDirectory, username, configFileFQN
+//	Errors: returned from NewNCClientWithOptions
+//	Verifications: None
 func NewNCClient(styhClientId, environment, password, secretKey, tempDirectory, username, configFileFQN string) (
 	NCClientPtr NCClient,
 	errorInfo pi.ErrorInfo,
 ) {
 
+	return NewNCClientWithOptions(
+		WithCredentials(
+			Credentials{
+				Password:     password,
+				SecretKey:    secretKey,
+				STYHClientId: styhClientId,
+				Username:     username,
+			},
+		),
+		WithEnvironment(environment),
+		WithTempDir(tempDirectory),
+		WithConfigFile(configFileFQN),
+	)
+}
+
+// NewNCClientWithOptions - creates an instance to connect to the NATS Connect server using the options provided.
+//
+//	Customer Messages: None
+//	Errors: ErrRequiredArgumentMissing, returned from validateConfiguration, LoadAWSCustomerSettings, Login, processAWSClientParameters, newTokenRefresher,
+//	BuildTemporaryFiles, BuildTLSTemporaryFiles, BuildInstanceName, getFileConnection, getInMemoryConnection
+//	Verifications: styhClientId, environment, password, secretKey, tempDirectory (unless WithInMemoryCredentials is used), username, configFileFQN
+func NewNCClientWithOptions(opts ...Option) (
+	NCClientPtr NCClient,
+	errorInfo pi.ErrorInfo,
+) {
+
 	var (
 		tEnvironment   string
+		tNATSConfig    ns.NATSConfiguration
+		tOptions       = newClientOptions(opts...)
 		tPassword      string
 		tSecretKey     string
 		tSTYHClientId  string
 		tTempDirectory string
+		tTokens        awss.CognitoTokens
 		tUsername      string
 	)
 
@@ -78,17 +113,30 @@ func NewNCClient(styhClientId, environment, password, secretKey, tempDirectory,
 		tConfigMap = make(map[string]interface{})
 	)
 
+	NCClientPtr.circuitBreakersPtr = newCircuitBreakers(tOptions.circuitBreaker)
+	NCClientPtr.compression = tOptions.compression
+	NCClientPtr.interceptors = tOptions.interceptors
+	NCClientPtr.loggerPtr = newLogger(tOptions.loggerPtr)
+	NCClientPtr.metricsCollectorPtr = tOptions.metricsCollectorPtr
+	NCClientPtr.rateLimiterPtr = newRateLimiter(tOptions.rateLimitPtr)
+	NCClientPtr.replyCachePtr = newReplyCache(tOptions.replyCache)
+	NCClientPtr.replyEnvelope = tOptions.replyEnvelope
+	NCClientPtr.requestTimeout = tOptions.requestTimeout
+	NCClientPtr.retryPolicy = tOptions.retryPolicy
+	NCClientPtr.subjectTimeouts = tOptions.subjectTimeouts
+	NCClientPtr.tracer = newTracer(tOptions.tracerProvider)
+
 	// Load arguments
-	if configFileFQN == ctv.VAL_EMPTY {
-		tSTYHClientId = styhClientId
-		tPassword = password
-		tSecretKey = secretKey
-		tTempDirectory = tempDirectory
-		tUsername = username
+	if tOptions.configFileFQN == ctv.VAL_EMPTY {
+		tSTYHClientId = tOptions.credentials.STYHClientId
+		tPassword = tOptions.credentials.Password
+		tSecretKey = tOptions.credentials.SecretKey
+		tTempDirectory = tOptions.tempDirectory
+		tUsername = tOptions.credentials.Username
 		// environment is validated in awss.NewAWSConfig
-		tEnvironment = environment
+		tEnvironment = tOptions.environment
 	} else {
-		if tConfigMap, errorInfo = cfgs.GetConfigFile(configFileFQN); errorInfo.Error != nil {
+		if tConfigMap, errorInfo = cfgs.GetConfigFile(tOptions.configFileFQN); errorInfo.Error != nil {
 			return
 		}
 		tSTYHClientId = tConfigMap[ctv.FN_STYH_CLIENT_ID].(string)
@@ -96,78 +144,304 @@ func NewNCClient(styhClientId, environment, password, secretKey, tempDirectory,
 		tPassword = tConfigMap[ctv.FN_PASSWORD].(string)
 		tConfigMap[ctv.FN_PASSWORD] = ctv.TXT_PROTECTED // Clear the password from memory.
 		tSecretKey = tConfigMap[ctv.FN_SECRET_KEY].(string)
-		tTempDirectory = tConfigMap[ctv.FN_TEMP_DIRECTORY].(string)
+		tTempDirectory, _ = tConfigMap[ctv.FN_TEMP_DIRECTORY].(string) // Optional with WithInMemoryCredentials, checked in validateConfiguration.
 		tUsername = tConfigMap[ctv.FN_USERNAME].(string)
 	}
+	tOptions.credentials.Password = ctv.TXT_PROTECTED  // Clear the password from memory.
+	tOptions.credentials.SecretKey = ctv.TXT_PROTECTED // Clear the secret key from memory.
 
-	if errorInfo = validateConfiguration(tSTYHClientId, tEnvironment, tSecretKey, tTempDirectory, tUsername, &tPassword); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
+	if errorInfo = validateConfiguration(
+		tSTYHClientId, tEnvironment, tSecretKey, tTempDirectory, tUsername, &tPassword, tOptions.inMemoryCredentials == false,
+	); errorInfo.Error != nil {
 		return
 	}
 
 	if NCClientPtr.awsSettings, errorInfo = awss.LoadAWSCustomerSettings(tEnvironment); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
 		return
 	}
+	NCClientPtr.loggerPtr.Debug(LOG_AWS_SETTINGS_LOADED, slog.String(LOG_KEY_ENVIRONMENT, tEnvironment))
 	NCClientPtr.environment = tEnvironment
-	NCClientPtr.tempDirectory = tTempDirectory
+	if tOptions.inMemoryCredentials == false {
+		NCClientPtr.tempDirectory = tTempDirectory
+	}
 
 	// This returns information about the STYH Customer
-	if NCClientPtr.styhCustomerConfig.tokens.Access,
-		NCClientPtr.styhCustomerConfig.tokens.ID,
-		NCClientPtr.styhCustomerConfig.tokens.Refresh, errorInfo = awss.Login(
+	if tTokens.Access, tTokens.ID, tTokens.Refresh, errorInfo = awss.Login(
 		ctv.AUTH_USER_SRP, tUsername, &tPassword,
 		NCClientPtr.awsSettings.STYHCognitoIdentityInfo, NCClientPtr.awsSettings.BaseConfig,
 	); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
 		return
 	}
+	NCClientPtr.loggerPtr.Debug(LOG_LOGGED_IN, slog.String(LOG_KEY_USERNAME, tUsername), slog.String(LOG_KEY_STYH_CLIENT_ID, tSTYHClientId))
 
 	NCClientPtr.styhCustomerConfig.clientId = tSTYHClientId
 	NCClientPtr.styhCustomerConfig.username = tUsername
 	NCClientPtr.styhCustomerConfig.secretKey = tSecretKey
 	tPassword = ctv.TXT_PROTECTED  // Clear the password from memory.
-	secretKey = ctv.TXT_PROTECTED  // Clear the secret key from memory.
 	tSecretKey = ctv.TXT_PROTECTED // Clear the secret key from memory.
 
 	// Gets needed information to make connection
-	if errorInfo = processAWSClientParameters(
+	if errorInfo = processAWSClientParameters(NCClientPtr.awsSettings, tTokens.ID, tEnvironment, &tNATSConfig); errorInfo.Error != nil {
+		return
+	}
+	NCClientPtr.loggerPtr.Debug(
+		LOG_PARAMETERS_LOADED,
+		slog.String(LOG_KEY_URL, tNATSConfig.NATSURL),
+		slog.Int(LOG_KEY_PORT, tNATSConfig.NATSPort),
+	)
+
+	// The credentials and TLS information are either written to the temporary directory or kept in memory
+	if tOptions.inMemoryCredentials == false {
+		// Creates needed file for NATS
+		if errorInfo = ns.BuildTemporaryFiles(NCClientPtr.tempDirectory, tNATSConfig); errorInfo.Error != nil {
+			_ = removeTemporaryFiles(NCClientPtr.tempDirectory) // Don't leave the files written before the failure on disk.
+			return
+		}
+		tNATSConfig.NATSCredentialsFilename = fmt.Sprintf("%v/%v", tTempDirectory, ns.CREDENTIAL_FILENAME)
+
+		// Creates needed file for NATS
+		if errorInfo = jwts.BuildTLSTemporaryFiles(NCClientPtr.tempDirectory, tNATSConfig.NATSTLSInfo); errorInfo.Error != nil {
+			_ = removeTemporaryFiles(NCClientPtr.tempDirectory) // Don't leave the credentials on disk.
+			return
+		}
+		tNATSConfig.NATSTLSInfo.TLSCABundleFQN = fmt.Sprintf("%v/%v", tTempDirectory, jwts.TLS_CA_BUNDLE_FILENAME)
+		tNATSConfig.NATSTLSInfo.TLSCertFQN = fmt.Sprintf("%v/%v", tTempDirectory, jwts.TLS_CERT_FILENAME)
+		tNATSConfig.NATSTLSInfo.TLSPrivateKeyFQN = fmt.Sprintf("%v/%v", tTempDirectory, jwts.TLS_PRIVATE_KEY_FILENAME)
+	}
+
+	// Keeps the Cognito tokens current so long-running clients can reload the parameters. From here on, the refresher holds
+	// the only copy of the tokens and the NATS configuration.
+	if NCClientPtr.tokenRefresherPtr, errorInfo = newTokenRefresher(
 		NCClientPtr.awsSettings,
-		NCClientPtr.styhCustomerConfig.tokens.ID,
+		tTokens,
 		tEnvironment,
-		&NCClientPtr.natsConfig,
+		NCClientPtr.tempDirectory,
+		tUsername,
+		tOptions.cognitoClientSecret,
+		tNATSConfig,
+		NCClientPtr.loggerPtr,
 	); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
+		_ = removeTemporaryFiles(NCClientPtr.tempDirectory) // Don't leave the credentials on disk.
 		return
 	}
 
-	// Creates needed file for NATS
-	if errorInfo = ns.BuildTemporaryFiles(NCClientPtr.tempDirectory, NCClientPtr.natsConfig); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
+	// Builds name for tracking
+	if NCClientPtr.natsService.InstanceName, errorInfo = ns.BuildInstanceName(ns.METHOD_DASHES, NCClientPtr.styhCustomerConfig.clientId); errorInfo.Error != nil {
+		_ = removeTemporaryFiles(NCClientPtr.tempDirectory) // Don't leave the credentials on disk.
 		return
 	}
-	NCClientPtr.natsConfig.NATSCredentialsFilename = fmt.Sprintf("%v/%v", tTempDirectory, ns.CREDENTIAL_FILENAME)
+	// Makes connection to the STYH NATS Server
+	if tOptions.inMemoryCredentials {
+		NCClientPtr.natsService.ConnPtr, errorInfo = getInMemoryConnection(
+			NCClientPtr.natsService.InstanceName,
+			NCClientPtr.tokenRefresherPtr.getNATSConfig,
+			tOptions.connectionHandlers,
+		)
+	} else {
+		NCClientPtr.natsService.ConnPtr, errorInfo = getFileConnection(
+			NCClientPtr.natsService.InstanceName,
+			NCClientPtr.tokenRefresherPtr.getNATSConfig(),
+			tOptions.connectionHandlers,
+		)
+	}
+	if errorInfo.Error != nil {
+		_ = removeTemporaryFiles(NCClientPtr.tempDirectory) // Don't leave the credentials on disk.
+		return
+	}
+	NCClientPtr.loggerPtr.Debug(
+		LOG_CONNECTED,
+		slog.String(LOG_KEY_INSTANCE_NAME, NCClientPtr.natsService.InstanceName),
+		slog.String(LOG_KEY_URL, NCClientPtr.natsService.ConnPtr.ConnectedUrlRedacted()),
+		slog.String(LOG_KEY_SERVER_ID, NCClientPtr.natsService.ConnPtr.ConnectedServerId()),
+		slog.Bool(LOG_KEY_IN_MEMORY_CREDENTIALS, tOptions.inMemoryCredentials),
+	)
+	NCClientPtr.metricsCollectorPtr.setConnection(NCClientPtr.natsService.ConnPtr)
+
+	NCClientPtr.tokenRefresherPtr.start()
+
+	return
+}
+
+// getFileConnection - will connect to the NATS server using the credentials and TLS files in the temporary directory. The
+// connection settings match ns.GetConnection, without writing the connection details to the standard log. The connection
+// handlers are registered as connect options.
+//
+//	Customer Messages: None
+//	Errors: ErrRequiredArgumentMissing, ErrGreatThanZero, returned from nats.Connect
+//	Verifications: None
+func getFileConnection(
+	instanceName string,
+	natsConfig ns.NATSConfiguration,
+	handlers ConnectionHandlers,
+) (
+	connPtr *nats.Conn,
+	errorInfo pi.ErrorInfo,
+) {
 
-	// Creates needed file for NATS
-	if errorInfo = jwts.BuildTLSTemporaryFiles(NCClientPtr.tempDirectory, NCClientPtr.natsConfig.NATSTLSInfo); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
+	var (
+		opts []nats.Option
+	)
+
+	if natsConfig.NATSURL == ctv.VAL_EMPTY {
+		errorInfo = pi.NewErrorInfo(pi.ErrRequiredArgumentMissing, fmt.Sprint(ctv.FN_URL))
+		return
+	}
+	if natsConfig.NATSPort == ctv.VAL_ZERO {
+		errorInfo = pi.NewErrorInfo(pi.ErrGreatThanZero, fmt.Sprint(ctv.FN_PORT))
 		return
 	}
-	NCClientPtr.natsConfig.NATSTLSInfo.TLSCABundleFQN = fmt.Sprintf("%v/%v", tTempDirectory, jwts.TLS_CA_BUNDLE_FILENAME)
-	NCClientPtr.natsConfig.NATSTLSInfo.TLSCertFQN = fmt.Sprintf("%v/%v", tTempDirectory, jwts.TLS_CERT_FILENAME)
-	NCClientPtr.natsConfig.NATSTLSInfo.TLSPrivateKeyFQN = fmt.Sprintf("%v/%v", tTempDirectory, jwts.TLS_PRIVATE_KEY_FILENAME)
 
-	// Builds name for tracking
-	if NCClientPtr.natsService.InstanceName, errorInfo = ns.BuildInstanceName(ns.METHOD_DASHES, NCClientPtr.styhCustomerConfig.clientId); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
+	opts = []nats.Option{
+		nats.Name(instanceName),             // Set a client name
+		nats.MaxReconnects(5),               // Set maximum reconnection attempts
+		nats.ReconnectWait(5 * time.Second), // Set reconnection wait time
+		nats.UserCredentials(natsConfig.NATSCredentialsFilename),
+		nats.RootCAs(natsConfig.NATSTLSInfo.TLSCABundleFQN),
+		nats.ClientCert(natsConfig.NATSTLSInfo.TLSCertFQN, natsConfig.NATSTLSInfo.TLSPrivateKeyFQN),
+	}
+	opts = append(opts, getConnectionHandlerOptions(handlers)...)
+
+	if connPtr, errorInfo.Error = nats.Connect(fmt.Sprintf("%v:%d", natsConfig.NATSURL, natsConfig.NATSPort), opts...); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v: %v", instanceName, ctv.TXT_SECURE_CONNECTION_FAILED))
 		return
 	}
-	// Makes connection to the STYH NATS Server
-	if NCClientPtr.natsService.ConnPtr, errorInfo = ns.GetConnection(NCClientPtr.natsService.InstanceName, NCClientPtr.natsConfig); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
+
+	return
+}
+
+// getInMemoryConnection - will connect to the NATS server without writing the credentials or TLS information to disk.
+// The credentials and TLS configuration are built from the SSM parameter values returned by natsConfigFunc. The function is
+// called each time the client connects, so reconnects use the latest token, client certificate, and CA bundle after a token
+// refresh.
+// The connection settings match ns.GetConnection. The connection handlers are registered as connect options.
+//
+//	Customer Messages: None
+//	Errors: ErrRequiredArgumentMissing, ErrGreatThanZero, returned from buildTLSConfig, nats.Connect
+//	Verifications: None
+func getInMemoryConnection(
+	instanceName string,
+	natsConfigFunc func() ns.NATSConfiguration,
+	handlers ConnectionHandlers,
+) (
+	connPtr *nats.Conn,
+	errorInfo pi.ErrorInfo,
+) {
+
+	var (
+		opts        []nats.Option
+		tNATSConfig = natsConfigFunc()
+		tTLSConfig  *tls.Config
+	)
+
+	if tNATSConfig.NATSURL == ctv.VAL_EMPTY {
+		errorInfo = pi.NewErrorInfo(pi.ErrRequiredArgumentMissing, fmt.Sprint(ctv.FN_URL))
+		return
+	}
+	if tNATSConfig.NATSPort == ctv.VAL_ZERO {
+		errorInfo = pi.NewErrorInfo(pi.ErrGreatThanZero, fmt.Sprint(ctv.FN_PORT))
+		return
+	}
+	if tNATSConfig.NATSToken == ctv.VAL_EMPTY {
+		errorInfo = pi.NewErrorInfo(pi.ErrRequiredArgumentMissing, fmt.Sprintf("%v%v", ctv.TXT_MISSING_PARAMETER, ctv.FN_TOKEN))
+		return
+	}
+	if tTLSConfig, errorInfo = buildTLSConfig(tNATSConfig.NATSTLSInfo); errorInfo.Error != nil {
+		return
+	}
+
+	opts = []nats.Option{
+		nats.Name(instanceName),             // Set a client name
+		nats.MaxReconnects(5),               // Set maximum reconnection attempts
+		nats.ReconnectWait(5 * time.Second), // Set reconnection wait time
+		nats.UserJWT(
+			func() (string, error) {
+				return nkeys.ParseDecoratedJWT([]byte(natsConfigFunc().NATSToken))
+			},
+			func(nonce []byte) ([]byte, error) {
+				var (
+					tKeyPair nkeys.KeyPair
+					tErr     error
+				)
+				if tKeyPair, tErr = nkeys.ParseDecoratedUserNKey([]byte(natsConfigFunc().NATSToken)); tErr != nil {
+					return nil, tErr
+				}
+				defer tKeyPair.Wipe()
+				return tKeyPair.Sign(nonce)
+			},
+		),
+		nats.Secure(tTLSConfig),
+		// The client certificate and root CAs are rebuilt on each handshake, so reconnects use the latest TLS information.
+		func(optionsPtr *nats.Options) error {
+			optionsPtr.TLSCertCB = func() (tls.Certificate, error) {
+				tTLSConfigPtr, tErrorInfo := buildTLSConfig(natsConfigFunc().NATSTLSInfo)
+				if tErrorInfo.Error != nil {
+					return tls.Certificate{}, tErrorInfo.Error
+				}
+				return tTLSConfigPtr.Certificates[0], nil
+			}
+			optionsPtr.RootCAsCB = func() (*x509.CertPool, error) {
+				tTLSConfigPtr, tErrorInfo := buildTLSConfig(natsConfigFunc().NATSTLSInfo)
+				if tErrorInfo.Error != nil {
+					return nil, tErrorInfo.Error
+				}
+				return tTLSConfigPtr.RootCAs, nil
+			}
+			return nil
+		},
+	}
+	opts = append(opts, getConnectionHandlerOptions(handlers)...)
+
+	if connPtr, errorInfo.Error = nats.Connect(fmt.Sprintf("%v:%d", tNATSConfig.NATSURL, tNATSConfig.NATSPort), opts...); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v: %v", instanceName, ctv.TXT_SECURE_CONNECTION_FAILED))
+		return
+	}
+
+	return
+}
+
+// buildTLSConfig - creates the TLS configuration from the certificate, private key, and CA bundle values.
+//
+//	Customer Messages: None
+//	Errors: ErrRequiredArgumentMissing, returned from tls.X509KeyPair
+//	Verifications: None
+func buildTLSConfig(tlsInfo jwts.TLSInfo) (
+	tlsConfigPtr *tls.Config,
+	errorInfo pi.ErrorInfo,
+) {
+
+	var (
+		tCertificate tls.Certificate
+		tRootCAsPtr  = x509.NewCertPool()
+	)
+
+	if tlsInfo.TLSCABundle == ctv.VAL_EMPTY {
+		errorInfo = pi.NewErrorInfo(pi.ErrRequiredArgumentMissing, fmt.Sprintf("%v%v", ctv.TXT_MISSING_PARAMETER, ctv.FN_TLS_CA_BUNDLE))
+		return
+	}
+	if tlsInfo.TLSCert == ctv.VAL_EMPTY {
+		errorInfo = pi.NewErrorInfo(pi.ErrRequiredArgumentMissing, fmt.Sprintf("%v%v", ctv.TXT_MISSING_PARAMETER, ctv.FN_TLS_CERTIFICATE))
+		return
+	}
+	if tlsInfo.TLSPrivateKey == ctv.VAL_EMPTY {
+		errorInfo = pi.NewErrorInfo(pi.ErrRequiredArgumentMissing, fmt.Sprintf("%v%v", ctv.TXT_MISSING_PARAMETER, ctv.FN_TLS_PRIVATE_KEY))
+		return
+	}
+
+	if tCertificate, errorInfo.Error = tls.X509KeyPair([]byte(tlsInfo.TLSCert), []byte(tlsInfo.TLSPrivateKey)); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.FN_TLS_CERTIFICATE)
+		return
+	}
+	if tRootCAsPtr.AppendCertsFromPEM([]byte(tlsInfo.TLSCABundle)) == false {
+		errorInfo = pi.NewErrorInfo(pi.ErrRequiredArgumentMissing, fmt.Sprintf("%v%v", ctv.TXT_MISSING_PARAMETER, ctv.FN_TLS_CA_BUNDLE))
 		return
 	}
 
+	tlsConfigPtr = &tls.Config{
+		Certificates: []tls.Certificate{tCertificate},
+		MinVersion:   tls.VersionTLS12,
+		RootCAs:      tRootCAsPtr,
+	}
+
 	return
 }
 
@@ -227,7 +501,124 @@ func processAWSClientParameters(
 	return
 }
 
-// validateConfiguration - Checks inputs are provided.
+// removeTemporaryFiles - securely deletes the NATS credentials and TLS files written by ns.BuildTemporaryFiles
+// and jwts.BuildTLSTemporaryFiles. Files that don't exist are skipped. All files are attempted and the first error is returned.
+//
+//	Customer Messages: None
+//	Errors: returned from shredFile
+//	Verifications: None
+func removeTemporaryFiles(tempDirectory string) (errorInfo pi.ErrorInfo) {
+
+	var (
+		tErrorInfo pi.ErrorInfo
+	)
+
+	if tempDirectory == ctv.VAL_EMPTY {
+		return
+	}
+
+	for _, filename := range []string{
+		ns.CREDENTIAL_FILENAME,
+		jwts.TLS_CA_BUNDLE_FILENAME,
+		jwts.TLS_CERT_FILENAME,
+		jwts.TLS_PRIVATE_KEY_FILENAME,
+	} {
+		if tErrorInfo = shredFile(fmt.Sprintf("%v/%v", tempDirectory, filename)); tErrorInfo.Error != nil && errorInfo.Error == nil {
+			errorInfo = tErrorInfo
+		}
+	}
+
+	return
+}
+
+// replaceTemporaryFiles - rewrites the NATS credentials file, the TLS files, or both. The new files are written to a staging
+// directory inside the temporary directory and then renamed over the old ones, so a reconnect never reads a partly written
+// file. The staging directory is securely deleted when a file can't be written.
+//
+//	Customer Messages: None
+//	Errors: returned from os.MkdirTemp, BuildTemporaryFiles, BuildTLSTemporaryFiles, os.Rename
+//	Verifications: None
+func replaceTemporaryFiles(tempDirectory string, natsConfig ns.NATSConfiguration, credentials, tls bool) (errorInfo pi.ErrorInfo) {
+
+	var (
+		tFilenames        []string
+		tStagingDirectory string
+	)
+
+	if credentials == false && tls == false {
+		return
+	}
+
+	if tStagingDirectory, errorInfo.Error = os.MkdirTemp(tempDirectory, TEMPORARY_STAGING_PATTERN); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v", ctv.TXT_DIRECTORY, tempDirectory))
+		return
+	}
+	defer func() {
+		_ = removeTemporaryFiles(tStagingDirectory) // Don't leave the credentials on disk.
+		_ = os.Remove(tStagingDirectory)
+	}()
+
+	if credentials {
+		if errorInfo = ns.BuildTemporaryFiles(tStagingDirectory, natsConfig); errorInfo.Error != nil {
+			return
+		}
+		tFilenames = append(tFilenames, ns.CREDENTIAL_FILENAME)
+	}
+	if tls {
+		if errorInfo = jwts.BuildTLSTemporaryFiles(tStagingDirectory, natsConfig.NATSTLSInfo); errorInfo.Error != nil {
+			return
+		}
+		tFilenames = append(tFilenames, jwts.TLS_CA_BUNDLE_FILENAME, jwts.TLS_CERT_FILENAME, jwts.TLS_PRIVATE_KEY_FILENAME)
+	}
+
+	for _, filename := range tFilenames {
+		if errorInfo.Error = os.Rename(fmt.Sprintf("%v/%v", tStagingDirectory, filename), fmt.Sprintf("%v/%v", tempDirectory, filename)); errorInfo.Error != nil {
+			errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v", ctv.TXT_FILENAME, filename))
+			return
+		}
+	}
+
+	return
+}
+
+// shredFile - overwrites the file contents with zeros before removing it, so the secrets are not left on disk.
+// If the file doesn't exist, nothing is done.
+//
+//	Customer Messages: None
+//	Errors: returned from os.OpenFile, Write, Sync, RemoveFile
+//	Verifications: None
+func shredFile(fqn string) (errorInfo pi.ErrorInfo) {
+
+	var (
+		tFilePtr  *os.File
+		tFileInfo os.FileInfo
+	)
+
+	if hv.DoesFileExist(fqn) == false {
+		return
+	}
+
+	if tFilePtr, errorInfo.Error = os.OpenFile(fqn, os.O_WRONLY, 0); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v", ctv.TXT_FILENAME, fqn))
+		return
+	}
+	if tFileInfo, errorInfo.Error = tFilePtr.Stat(); errorInfo.Error == nil {
+		if _, errorInfo.Error = tFilePtr.Write(make([]byte, tFileInfo.Size())); errorInfo.Error == nil {
+			errorInfo.Error = tFilePtr.Sync()
+		}
+	}
+	_ = tFilePtr.Close()
+	if errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v", ctv.TXT_FILENAME, fqn))
+		return
+	}
+
+	errorInfo = hv.RemoveFile(fqn)
+
+	return
+}
+
+// validateConfiguration - Checks inputs are provided. The temporary directory is only checked when it is required.
 //
 //	Customer Messages: None
 //	Errors: ErrRequiredArgumentMissing, ErrEnvironmentInvalid
@@ -235,6 +626,7 @@ func processAWSClientParameters(
 func validateConfiguration(
 	styhClientId, environment, secretKey, tempDirectory, username string,
 	passwordPtr *string,
+	tempDirectoryRequired bool,
 ) (
 	errorInfo pi.ErrorInfo,
 ) {
@@ -255,7 +647,7 @@ func validateConfiguration(
 		errorInfo = pi.NewErrorInfo(pi.ErrRequiredArgumentMissing, fmt.Sprintf("%v%v", ctv.TXT_MISSING_PARAMETER, ctv.FN_SECRET_KEY))
 		return
 	}
-	if tempDirectory == ctv.VAL_EMPTY {
+	if tempDirectoryRequired && tempDirectory == ctv.VAL_EMPTY {
 		errorInfo = pi.NewErrorInfo(pi.ErrRequiredArgumentMissing, fmt.Sprintf("%v%v", ctv.TXT_MISSING_PARAMETER, ctv.FN_TEMP_DIRECTORY))
 		return
 	}
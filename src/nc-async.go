@@ -0,0 +1,205 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+// Awaitable - a request started with DoAsync. It is used by WaitAll, so futures with different reply types can be awaited together.
+type Awaitable interface {
+	Done() <-chan struct{} // Closed when the request completes.
+	Err() pi.ErrorInfo     // The request error. It is only set after Done is closed.
+	RequestId() string     // The request id sent with the request.
+}
+
+// Future - the pending reply of a request started with DoAsync.
+type Future[Reply any] struct {
+	done        chan struct{}
+	errorInfo   pi.ErrorInfo
+	reply       Reply
+	requestId   string
+	requestType string
+}
+
+// DoAsync - starts the request in its own goroutine and returns without waiting for the reply. Requests started this way
+// share the client's NATS connection and are in flight at the same time. The endpoint, context and call options are used as
+// they are by Do, so cancelling the context cancels the request. The request id is set before the request starts.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func DoAsync[Request any, Reply any](
+	ctx context.Context,
+	clientPtr *NCClient,
+	requestEndpoint Endpoint[Request, Reply],
+	request Request,
+	callOptions ...CallOption,
+) (
+	futurePtr *Future[Reply],
+) {
+
+	futurePtr = &Future[Reply]{
+		done:        make(chan struct{}),
+		requestId:   newRequestId(newCallOptions(callOptions...).requestId),
+		requestType: fmt.Sprintf("%T", request),
+	}
+
+	go func() {
+		defer close(futurePtr.done)
+		futurePtr.reply, futurePtr.errorInfo = Do(
+			ctx,
+			clientPtr,
+			requestEndpoint,
+			request,
+			append(callOptions[:len(callOptions):len(callOptions)], WithCallRequestId(futurePtr.requestId))...,
+		)
+	}()
+
+	return
+}
+
+// WaitAll - waits until every future is done or the context is done. The error of the first failed future, in the order
+// given, is returned. Futures that are still running when the context is done keep running until their own context ends,
+// and their request ids are added to the context error.
+//
+//	Customer Messages: None
+//	Errors: returned from the futures, ctx.Err
+//	Verifications: None
+func WaitAll(ctx context.Context, futures ...Awaitable) (errorInfo pi.ErrorInfo) {
+
+	var (
+		tRequestIds []string
+	)
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for _, future := range futures {
+		if future == nil {
+			continue
+		}
+		select {
+		case <-future.Done():
+		case <-ctx.Done():
+			for _, runningFuture := range futures {
+				if runningFuture != nil && isDone(runningFuture) == false {
+					tRequestIds = append(tRequestIds, runningFuture.RequestId())
+				}
+			}
+			errorInfo = pi.NewErrorInfo(
+				ctx.Err(),
+				fmt.Sprintf("%v%d%v%v", TXT_FUTURES, len(futures), TXT_REQUEST_IDS, strings.Join(tRequestIds, ", ")),
+			)
+			return
+		}
+	}
+
+	for _, future := range futures {
+		if future == nil {
+			continue
+		}
+		if errorInfo = future.Err(); errorInfo.Error != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Await - waits for the reply. If the context is done first, its error is returned and the request keeps running, so
+// Await can be called again.
+//
+//	Customer Messages: None
+//	Errors: returned from Do, ctx.Err
+//	Verifications: None
+func (futurePtr *Future[Reply]) Await(ctx context.Context) (reply Reply, errorInfo pi.ErrorInfo) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	select {
+	case <-futurePtr.done:
+		return futurePtr.reply, futurePtr.errorInfo
+	case <-ctx.Done():
+		errorInfo = pi.NewErrorInfo(ctx.Err(), fmt.Sprintf("%v%v%v%v", TXT_REQUEST_TYPE, futurePtr.requestType, TXT_REQUEST_ID, futurePtr.requestId))
+		return
+	}
+}
+
+// Done - returns a channel that is closed when the request completes.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (futurePtr *Future[Reply]) Done() <-chan struct{} {
+
+	return futurePtr.done
+}
+
+// Err - returns the request error once Done is closed. Before that, an empty ErrorInfo is returned.
+//
+//	Customer Messages: None
+//	Errors: returned from Do
+//	Verifications: None
+func (futurePtr *Future[Reply]) Err() (errorInfo pi.ErrorInfo) {
+
+	select {
+	case <-futurePtr.done:
+		return futurePtr.errorInfo
+	default:
+		return
+	}
+}
+
+// RequestId - returns the request id sent with the request. It is set when DoAsync returns.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (futurePtr *Future[Reply]) RequestId() string {
+
+	return futurePtr.requestId
+}
+
+// isDone - returns true when the future's Done channel is closed.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func isDone(future Awaitable) bool {
+
+	select {
+	case <-future.Done():
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,74 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	ncs "github.com/sty-holdings/nats-connect-shared/v2024"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+func TestFutureContextErrors(tPtr *testing.T) {
+
+	var (
+		tCancel     context.CancelFunc
+		tClientPtr  = newMockClient(mockGetTeam)
+		tCtx        context.Context
+		tDoneCtx    context.Context
+		tErrorInfo  pi.ErrorInfo
+		tFinished   *Future[ncs.GetTeamReply]
+		tRunning    *Future[ncs.GetTeamReply]
+		tStopFuture context.CancelFunc
+	)
+
+	tCtx, tStopFuture = context.WithCancel(context.Background())
+	defer tStopFuture()
+	tRunning = DoAsync(tCtx, tClientPtr, SynaidaGetTeamEndpoint, ncs.GetTeamRequest{TeamId: "WAIT"})
+	tFinished = DoAsync(context.Background(), tClientPtr, SynaidaGetTeamEndpoint, ncs.GetTeamRequest{TeamId: "T1"}, WithCallRequestId("REQUEST_ID"))
+	<-tFinished.Done()
+
+	if tFinished.RequestId() != "REQUEST_ID" || tRunning.RequestId() == "" {
+		tPtr.Errorf("got request ids %q and %q, want REQUEST_ID and a new id", tFinished.RequestId(), tRunning.RequestId())
+	}
+
+	tDoneCtx, tCancel = context.WithCancel(context.Background())
+	tCancel()
+
+	_, tErrorInfo = tRunning.Await(tDoneCtx)
+	if errors.Is(tErrorInfo.Error, context.Canceled) == false || strings.Contains(tErrorInfo.AdditionalInfo, tRunning.RequestId()) == false {
+		tPtr.Errorf("got Await error %v with %q, want %v with the request id", tErrorInfo.Error, tErrorInfo.AdditionalInfo, context.Canceled)
+	}
+
+	tErrorInfo = WaitAll(tDoneCtx, tFinished, tRunning)
+	if errors.Is(tErrorInfo.Error, context.Canceled) == false || strings.Contains(tErrorInfo.AdditionalInfo, tRunning.RequestId()) == false {
+		tPtr.Errorf("got WaitAll error %v with %q, want %v with the running request id", tErrorInfo.Error, tErrorInfo.AdditionalInfo, context.Canceled)
+	}
+	if strings.Contains(tErrorInfo.AdditionalInfo, tFinished.RequestId()) {
+		tPtr.Errorf("got WaitAll error info %q, want only the running request ids", tErrorInfo.AdditionalInfo)
+	}
+}
@@ -0,0 +1,220 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+//goland:noinspection ALL
+const (
+	DEFAULT_BATCH_CONCURRENCY = 4
+)
+
+// BatchOption - configures a batch run by DoBatch.
+type BatchOption func(batchOptionsPtr *batchOptions)
+
+// BatchResult - the reply and error for one request in a batch.
+type BatchResult[Reply any] struct {
+	ErrorInfo pi.ErrorInfo
+	Reply     Reply
+	RequestId string // Set for every request, including those that weren't sent.
+}
+
+type batchOptions struct {
+	callOptions []CallOption
+	concurrency int
+	failFast    bool
+	timeout     time.Duration
+}
+
+// WithBatchCallOptions - applies the call options to every request in the batch.
+func WithBatchCallOptions(callOptions ...CallOption) BatchOption {
+
+	return func(batchOptionsPtr *batchOptions) {
+		batchOptionsPtr.callOptions = append(batchOptionsPtr.callOptions, callOptions...)
+	}
+}
+
+// WithBatchConcurrency - sets how many requests in the batch are in flight at the same time. The default is DEFAULT_BATCH_CONCURRENCY.
+func WithBatchConcurrency(concurrency int) BatchOption {
+
+	return func(batchOptionsPtr *batchOptions) {
+		batchOptionsPtr.concurrency = concurrency
+	}
+}
+
+// WithBatchFailFast - stops the batch when a request fails. Requests in flight are cancelled, and requests not yet sent get
+// ErrBatchStopped.
+func WithBatchFailFast() BatchOption {
+
+	return func(batchOptionsPtr *batchOptions) {
+		batchOptionsPtr.failFast = true
+	}
+}
+
+// WithBatchTimeout - sets a deadline shared by the whole batch. Each request still has its own timeout within it, the call,
+// subject, or client timeout, whichever applies, ending no later than the deadline. Requests not sent before the deadline get
+// context.DeadlineExceeded.
+func WithBatchTimeout(timeout time.Duration) BatchOption {
+
+	return func(batchOptionsPtr *batchOptions) {
+		batchOptionsPtr.timeout = timeout
+	}
+}
+
+// DoBatch - sends the requests to the endpoint with bounded concurrency on the client's NATS connection. The results are in
+// the same order as the requests. Each request gets its request id before the batch starts, so requests that aren't sent
+// have one too. The returned ErrorInfo is the first error to occur, so check each result when it is set.
+//
+//	Customer Messages: None
+//	Errors: ErrBatchStopped, returned from Do
+//	Verifications: None
+func DoBatch[Request any, Reply any](
+	ctx context.Context,
+	clientPtr *NCClient,
+	requestEndpoint Endpoint[Request, Reply],
+	requests []Request,
+	batchOpts ...BatchOption,
+) (
+	results []BatchResult[Reply],
+	errorInfo pi.ErrorInfo,
+) {
+
+	var (
+		tCancel      context.CancelFunc
+		tIndexes     = make(chan int)
+		tMutex       sync.Mutex
+		tOptions     = newBatchOptions(batchOpts...)
+		tRequestId   string
+		tSkip        func(index int)
+		tStopped     bool
+		tWaitGroup   sync.WaitGroup
+		tWorkerCount int
+	)
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if tOptions.timeout > 0 {
+		ctx, tCancel = context.WithTimeout(ctx, tOptions.timeout)
+	} else {
+		ctx, tCancel = context.WithCancel(ctx)
+	}
+	defer tCancel()
+
+	results = make([]BatchResult[Reply], len(requests))
+	tRequestId = newCallOptions(tOptions.callOptions...).requestId
+	for index := range results {
+		results[index].RequestId = newRequestId(tRequestId)
+	}
+	if tWorkerCount = min(tOptions.concurrency, len(requests)); tWorkerCount == 0 {
+		return
+	}
+
+	// Requests that aren't sent get ErrBatchStopped after a failure with fail fast, otherwise the context error.
+	tSkip = func(index int) {
+		tMutex.Lock()
+		defer tMutex.Unlock()
+		if tStopped {
+			results[index].ErrorInfo = pi.NewErrorInfo(ErrBatchStopped, fmt.Sprintf("%v%d%v%v", TXT_BATCH_INDEX, index, TXT_REQUEST_ID, results[index].RequestId))
+			return
+		}
+		results[index].ErrorInfo = pi.NewErrorInfo(ctx.Err(), fmt.Sprintf("%v%d%v%v", TXT_BATCH_INDEX, index, TXT_REQUEST_ID, results[index].RequestId))
+		if errorInfo.Error == nil {
+			errorInfo = results[index].ErrorInfo
+		}
+	}
+
+	for i := 0; i < tWorkerCount; i++ {
+		tWaitGroup.Add(1)
+		go func() {
+			defer tWaitGroup.Done()
+			for index := range tIndexes {
+				if ctx.Err() != nil { // The batch stopped after the request was handed over.
+					tSkip(index)
+					continue
+				}
+				results[index].Reply, results[index].ErrorInfo = Do(
+					ctx,
+					clientPtr,
+					requestEndpoint,
+					requests[index],
+					append(tOptions.callOptions[:len(tOptions.callOptions):len(tOptions.callOptions)], WithCallRequestId(results[index].RequestId))...,
+				)
+				if results[index].ErrorInfo.Error == nil {
+					continue
+				}
+				tMutex.Lock()
+				if errorInfo.Error == nil {
+					errorInfo = results[index].ErrorInfo
+				}
+				if tOptions.failFast {
+					tStopped = true
+					tCancel()
+				}
+				tMutex.Unlock()
+			}
+		}()
+	}
+
+	for index := range requests {
+		if ctx.Err() == nil {
+			select {
+			case tIndexes <- index:
+				continue
+			case <-ctx.Done():
+			}
+		}
+		tSkip(index)
+	}
+	close(tIndexes)
+	tWaitGroup.Wait()
+
+	return
+}
+
+// newBatchOptions - applies the batch options over the defaults.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func newBatchOptions(batchOpts ...BatchOption) (options batchOptions) {
+
+	for _, batchOpt := range batchOpts {
+		if batchOpt != nil {
+			batchOpt(&options)
+		}
+	}
+	if options.concurrency <= 0 {
+		options.concurrency = DEFAULT_BATCH_CONCURRENCY
+	}
+
+	return
+}
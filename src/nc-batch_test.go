@@ -0,0 +1,208 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ncs "github.com/sty-holdings/nats-connect-shared/v2024"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+func TestDoBatch(tPtr *testing.T) {
+
+	var (
+		tests = []struct {
+			name          string
+			teamIds       []string
+			batchOpts     []BatchOption
+			requestTimout time.Duration
+			wantErrors    []error // By request. Nil means the reply has the team id.
+			wantError     error   // The returned error.
+		}{
+			{
+				name:       "Positive Case: Results are in request order.",
+				teamIds:    []string{"T0", "T1", "T2", "T3", "T4", "T5", "T6", "T7", "T8", "T9"},
+				batchOpts:  []BatchOption{WithBatchConcurrency(4)},
+				wantErrors: make([]error, 10),
+			},
+			{
+				name:       "Negative Case: Without fail fast, every request is sent.",
+				teamIds:    []string{"T0", "FAIL", "T2", "T3"},
+				batchOpts:  []BatchOption{WithBatchConcurrency(1)},
+				wantErrors: []error{nil, ErrNotFound, nil, nil},
+				wantError:  ErrNotFound,
+			},
+			{
+				name:       "Negative Case: Fail fast stops the requests not yet sent.",
+				teamIds:    []string{"T0", "FAIL", "T2", "T3"},
+				batchOpts:  []BatchOption{WithBatchConcurrency(1), WithBatchFailFast()},
+				wantErrors: []error{nil, ErrNotFound, ErrBatchStopped, ErrBatchStopped},
+				wantError:  ErrNotFound,
+			},
+			{
+				name:       "Negative Case: Requests not sent before the batch deadline.",
+				teamIds:    []string{"WAIT", "T1"},
+				batchOpts:  []BatchOption{WithBatchConcurrency(1), WithBatchTimeout(50 * time.Millisecond)},
+				wantErrors: []error{context.DeadlineExceeded, context.DeadlineExceeded},
+				wantError:  context.DeadlineExceeded,
+			},
+			{
+				name:          "Negative Case: Each request keeps its own timeout within the batch deadline.",
+				teamIds:       []string{"WAIT", "T1"},
+				batchOpts:     []BatchOption{WithBatchConcurrency(1), WithBatchTimeout(time.Minute)},
+				requestTimout: 50 * time.Millisecond,
+				wantErrors:    []error{context.DeadlineExceeded, nil},
+				wantError:     context.DeadlineExceeded,
+			},
+			{
+				name: "Positive Case: An empty batch.",
+			},
+		}
+	)
+
+	for _, ts := range tests {
+		tPtr.Run(
+			ts.name, func(t *testing.T) {
+				var (
+					tClientPtr = newMockClient(mockGetTeam)
+					tErrorInfo pi.ErrorInfo
+					tRequests  []ncs.GetTeamRequest
+					tResults   []BatchResult[ncs.GetTeamReply]
+				)
+
+				if ts.requestTimout > 0 {
+					tClientPtr.requestTimeout = ts.requestTimout
+				}
+				for _, teamId := range ts.teamIds {
+					tRequests = append(tRequests, ncs.GetTeamRequest{TeamId: teamId})
+				}
+
+				tResults, tErrorInfo = DoBatch(context.Background(), tClientPtr, SynaidaGetTeamEndpoint, tRequests, ts.batchOpts...)
+				if errors.Is(tErrorInfo.Error, ts.wantError) == false {
+					t.Errorf("%v: got error %v, want %v", ts.name, tErrorInfo.Error, ts.wantError)
+				}
+				if len(tResults) != len(tRequests) {
+					t.Fatalf("%v: got %d results, want %d", ts.name, len(tResults), len(tRequests))
+				}
+				for i, result := range tResults {
+					if errors.Is(result.ErrorInfo.Error, ts.wantErrors[i]) == false {
+						t.Errorf("%v: result %d: got error %v, want %v", ts.name, i, result.ErrorInfo.Error, ts.wantErrors[i])
+					}
+					if result.RequestId == "" || strings.Contains(result.ErrorInfo.AdditionalInfo, result.RequestId) == (result.ErrorInfo.Error == nil) {
+						t.Errorf("%v: result %d: got request id %q and error info %q, want the id in the error", ts.name, i, result.RequestId, result.ErrorInfo.AdditionalInfo)
+					}
+					if tReplyData, _ := json.Marshal(result.Reply); ts.wantErrors[i] == nil && strings.Contains(string(tReplyData), `"`+ts.teamIds[i]+`"`) == false {
+						t.Errorf("%v: result %d: got reply %s, want team %v", ts.name, i, tReplyData, ts.teamIds[i])
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestDoBatchConcurrency(tPtr *testing.T) {
+
+	var (
+		tFull        = make(chan struct{})
+		tFullOnce    sync.Once
+		tInFlight    atomic.Int32
+		tMaxInFlight atomic.Int32
+		tClientPtr   = newMockClient(
+			func(ctx context.Context, callPtr *Call, next Invoker) pi.ErrorInfo {
+				tCount := tInFlight.Add(1)
+				defer tInFlight.Add(-1)
+				for tMax := tMaxInFlight.Load(); tCount > tMax && tMaxInFlight.CompareAndSwap(tMax, tCount) == false; tMax = tMaxInFlight.Load() {
+				}
+				// Hold the first requests until the batch has three in flight.
+				if tCount >= 3 {
+					tFullOnce.Do(func() { close(tFull) })
+				}
+				select {
+				case <-tFull:
+				case <-ctx.Done():
+					return pi.NewErrorInfo(ctx.Err(), "fewer than 3 requests in flight")
+				}
+				return mockGetTeam(ctx, callPtr, next)
+			},
+		)
+		tRequests = make([]ncs.GetTeamRequest, 20)
+	)
+
+	if _, tErrorInfo := DoBatch(context.Background(), tClientPtr, SynaidaGetTeamEndpoint, tRequests, WithBatchConcurrency(3)); tErrorInfo.Error != nil {
+		tPtr.Fatalf("got error %v", tErrorInfo.Error)
+	}
+	if tMaxInFlight.Load() != 3 {
+		tPtr.Errorf("got %d requests in flight, want 3", tMaxInFlight.Load())
+	}
+}
+
+// mockGetTeam - answers a GetTeamRequest with its team id. Team FAIL returns ErrNotFound, and team WAIT waits until the
+// context is done.
+func mockGetTeam(ctx context.Context, callPtr *Call, next Invoker) (errorInfo pi.ErrorInfo) {
+
+	var (
+		tRequest = callPtr.Request.(ncs.GetTeamRequest)
+	)
+
+	switch tRequest.TeamId {
+	case "FAIL":
+		return pi.NewErrorInfo(ErrNotFound, fmt.Sprintf("team %v", tRequest.TeamId))
+	case "WAIT":
+		<-ctx.Done()
+		return pi.NewErrorInfo(ctx.Err(), fmt.Sprintf("team %v", tRequest.TeamId))
+	}
+
+	callPtr.ReplyData = []byte(fmt.Sprintf(`{"response":{"team_id":%q}}`, tRequest.TeamId))
+	if errorInfo.Error = json.Unmarshal(callPtr.ReplyData, callPtr.ReplyPtr); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, callPtr.Subject)
+	}
+
+	return
+}
+
+// newMockClient - returns a client whose requests are answered by the mock instead of the NATS connection. The requests are
+// still marshalled and encrypted.
+func newMockClient(mock Interceptor) (clientPtr *NCClient) {
+
+	clientPtr = &NCClient{
+		interceptors:   []Interceptor{mock},
+		requestTimeout: time.Second,
+		retryPolicy:    NoRetryPolicy(),
+	}
+	clientPtr.styhCustomerConfig.clientId = "CLIENT_ID"
+	clientPtr.styhCustomerConfig.secretKey = base64.StdEncoding.EncodeToString(make([]byte, 32))
+	clientPtr.styhCustomerConfig.username = "USERNAME"
+
+	return
+}
@@ -0,0 +1,320 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	ctv "github.com/sty-holdings/constant-type-vars-go/v2024"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+//goland:noinspection ALL
+const (
+	CIRCUIT_CLOSED    CircuitState = "closed"
+	CIRCUIT_HALF_OPEN CircuitState = "half-open"
+	CIRCUIT_OPEN      CircuitState = "open"
+	//
+	DEFAULT_CIRCUIT_FAILURE_THRESHOLD = 5
+	DEFAULT_CIRCUIT_HALF_OPEN_MAX     = 1
+	DEFAULT_CIRCUIT_OPEN_TIMEOUT      = 30 * time.Second
+)
+
+// CircuitState - the state of a subject's circuit breaker. Closed sends requests, open fails them immediately, and half-open
+// sends a limited number of trial requests to decide whether to close again.
+type CircuitState string
+
+// CircuitBreakerSettings - controls the per-subject circuit breakers. A FailureThreshold of zero turns the breakers off.
+type CircuitBreakerSettings struct {
+	FailureErrors       []error       // Errors, matched using errors.Is, that count as failures.
+	FailureThreshold    int           // Consecutive failures that open the circuit.
+	HalfOpenMaxRequests int           // Trial requests allowed at the same time while half-open.
+	OpenTimeout         time.Duration // How long the circuit stays open before it becomes half-open.
+}
+
+// CircuitOpenError - is returned, without sending the request, while the subject's circuit is open.
+// It matches ErrCircuitOpen using errors.Is.
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+	Subject    string
+}
+
+type circuitBreaker struct {
+	consecutiveFailures int
+	generation          uint64 // Changes with the state, so outcomes of requests let through in an earlier state are ignored.
+	halfOpenInFlight    int
+	openedAt            time.Time
+	state               CircuitState
+}
+
+type circuitBreakers struct {
+	breakers map[string]*circuitBreaker
+	mutex    sync.Mutex
+	settings CircuitBreakerSettings
+}
+
+// DefaultCircuitBreakerSettings - returns the settings used by the client: the circuit opens after five consecutive
+// timeouts or no responders errors and stays open for 30 seconds.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func DefaultCircuitBreakerSettings() CircuitBreakerSettings {
+
+	return CircuitBreakerSettings{
+		FailureErrors:       []error{nats.ErrNoResponders, nats.ErrTimeout, ErrRequestTimeout},
+		FailureThreshold:    DEFAULT_CIRCUIT_FAILURE_THRESHOLD,
+		HalfOpenMaxRequests: DEFAULT_CIRCUIT_HALF_OPEN_MAX,
+		OpenTimeout:         DEFAULT_CIRCUIT_OPEN_TIMEOUT,
+	}
+}
+
+// Error - returns the error message with the subject and how long until a trial request is allowed.
+func (errorPtr *CircuitOpenError) Error() string {
+
+	return fmt.Sprintf("%v %v%v Retry After: %v", CIRCUIT_IS_OPEN, ctv.TXT_SUBJECT, errorPtr.Subject, errorPtr.RetryAfter.Round(time.Millisecond))
+}
+
+// Unwrap - allows errors.Is to match ErrCircuitOpen.
+func (errorPtr *CircuitOpenError) Unwrap() error {
+
+	return ErrCircuitOpen
+}
+
+// CircuitBreakerState - returns the state of the subject's circuit breaker. Subjects that haven't been used are closed.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) CircuitBreakerState(subject string) (state CircuitState) {
+
+	if clientPtr.circuitBreakersPtr == nil {
+		return CIRCUIT_CLOSED
+	}
+
+	clientPtr.circuitBreakersPtr.mutex.Lock()
+	defer clientPtr.circuitBreakersPtr.mutex.Unlock()
+
+	return clientPtr.circuitBreakersPtr.getState(subject, time.Now())
+}
+
+// CircuitBreakerStates - returns the state of every subject that has been used, for health endpoints.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) CircuitBreakerStates() (states map[string]CircuitState) {
+
+	var (
+		tNow = time.Now()
+	)
+
+	states = make(map[string]CircuitState)
+	if clientPtr.circuitBreakersPtr == nil {
+		return
+	}
+
+	clientPtr.circuitBreakersPtr.mutex.Lock()
+	defer clientPtr.circuitBreakersPtr.mutex.Unlock()
+
+	for subject := range clientPtr.circuitBreakersPtr.breakers {
+		states[subject] = clientPtr.circuitBreakersPtr.getState(subject, tNow)
+	}
+
+	return
+}
+
+// newCircuitBreakers - creates the per-subject breakers. Nil is returned when the settings turn the breakers off.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func newCircuitBreakers(settings CircuitBreakerSettings) (breakersPtr *circuitBreakers) {
+
+	if settings.FailureThreshold <= 0 {
+		return
+	}
+	if settings.HalfOpenMaxRequests <= 0 {
+		settings.HalfOpenMaxRequests = DEFAULT_CIRCUIT_HALF_OPEN_MAX
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = DEFAULT_CIRCUIT_OPEN_TIMEOUT
+	}
+
+	return &circuitBreakers{
+		breakers: make(map[string]*circuitBreaker),
+		settings: settings,
+	}
+}
+
+// allow - checks the subject's circuit before a request is sent. The returned generation is passed to record with the
+// outcome. A CircuitOpenError is returned while the circuit is open or the half-open trial requests are in use.
+//
+//	Customer Messages: None
+//	Errors: CircuitOpenError
+//	Verifications: None
+func (breakersPtr *circuitBreakers) allow(subject string) (generation uint64, errorInfo pi.ErrorInfo) {
+
+	var (
+		tBreakerPtr *circuitBreaker
+		tNow        = time.Now()
+	)
+
+	if breakersPtr == nil {
+		return
+	}
+
+	breakersPtr.mutex.Lock()
+	defer breakersPtr.mutex.Unlock()
+
+	tBreakerPtr = breakersPtr.getBreaker(subject)
+	switch breakersPtr.getState(subject, tNow) {
+	case CIRCUIT_OPEN:
+		errorInfo = pi.NewErrorInfo(
+			&CircuitOpenError{RetryAfter: tBreakerPtr.openedAt.Add(breakersPtr.settings.OpenTimeout).Sub(tNow), Subject: subject},
+			fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, subject),
+		)
+	case CIRCUIT_HALF_OPEN:
+		if tBreakerPtr.halfOpenInFlight >= breakersPtr.settings.HalfOpenMaxRequests {
+			errorInfo = pi.NewErrorInfo(&CircuitOpenError{Subject: subject}, fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, subject))
+			return
+		}
+		if tBreakerPtr.state == CIRCUIT_OPEN {
+			tBreakerPtr.setState(CIRCUIT_HALF_OPEN)
+		}
+		tBreakerPtr.halfOpenInFlight++
+	}
+
+	return tBreakerPtr.generation, errorInfo
+}
+
+// record - updates the subject's circuit with the outcome of a request that allow let through. Outcomes of requests let
+// through before the state last changed are ignored, so only trial requests decide a half-open circuit. Errors that aren't
+// failure errors, such as a cancelled context, count as neither a success nor a failure.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (breakersPtr *circuitBreakers) record(subject string, generation uint64, err error) {
+
+	var (
+		tBreakerPtr *circuitBreaker
+		tFailure    bool
+	)
+
+	if breakersPtr == nil {
+		return
+	}
+
+	for _, failureError := range breakersPtr.settings.FailureErrors {
+		if errors.Is(err, failureError) {
+			tFailure = true
+			break
+		}
+	}
+
+	breakersPtr.mutex.Lock()
+	defer breakersPtr.mutex.Unlock()
+
+	tBreakerPtr = breakersPtr.getBreaker(subject)
+	if generation != tBreakerPtr.generation {
+		return
+	}
+	if tBreakerPtr.state == CIRCUIT_HALF_OPEN {
+		tBreakerPtr.halfOpenInFlight--
+	}
+
+	switch {
+	case err == nil:
+		tBreakerPtr.consecutiveFailures = 0
+		if tBreakerPtr.state == CIRCUIT_HALF_OPEN {
+			tBreakerPtr.setState(CIRCUIT_CLOSED)
+		}
+	case tFailure:
+		tBreakerPtr.consecutiveFailures++
+		if tBreakerPtr.state == CIRCUIT_HALF_OPEN || tBreakerPtr.consecutiveFailures >= breakersPtr.settings.FailureThreshold {
+			tBreakerPtr.openedAt = time.Now()
+			tBreakerPtr.setState(CIRCUIT_OPEN)
+		}
+	}
+}
+
+// getBreaker - returns the subject's breaker, creating a closed one if needed. The caller must hold the mutex.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (breakersPtr *circuitBreakers) getBreaker(subject string) (breakerPtr *circuitBreaker) {
+
+	var (
+		ok bool
+	)
+
+	if breakerPtr, ok = breakersPtr.breakers[subject]; ok == false {
+		breakerPtr = &circuitBreaker{state: CIRCUIT_CLOSED}
+		breakersPtr.breakers[subject] = breakerPtr
+	}
+
+	return
+}
+
+// getState - returns the subject's state, reporting an open circuit as half-open once OpenTimeout has passed.
+// The caller must hold the mutex.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (breakersPtr *circuitBreakers) getState(subject string, now time.Time) CircuitState {
+
+	var (
+		tBreakerPtr *circuitBreaker
+		ok          bool
+	)
+
+	if tBreakerPtr, ok = breakersPtr.breakers[subject]; ok == false {
+		return CIRCUIT_CLOSED
+	}
+	if tBreakerPtr.state == CIRCUIT_OPEN && now.Sub(tBreakerPtr.openedAt) >= breakersPtr.settings.OpenTimeout {
+		return CIRCUIT_HALF_OPEN
+	}
+
+	return tBreakerPtr.state
+}
+
+// setState - moves the breaker to the state and starts a new generation. The caller must hold the mutex.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (breakerPtr *circuitBreaker) setState(state CircuitState) {
+
+	breakerPtr.generation++
+	breakerPtr.halfOpenInFlight = 0
+	breakerPtr.state = state
+}
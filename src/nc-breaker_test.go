@@ -0,0 +1,196 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+func TestCircuitBreakers(tPtr *testing.T) {
+
+	type step struct {
+		allow     bool          // Call allow, otherwise record err for the admission.
+		admission int           // The allow step, counting allow steps only, whose request finishes.
+		err       error         // The outcome passed to record.
+		expire    bool          // Move openedAt back by OpenTimeout before the step.
+		wantErr   error         // What allow returns.
+		wantState CircuitState  // The state after the step.
+		wantAfter time.Duration // The minimum RetryAfter when allow returns a CircuitOpenError.
+	}
+
+	var (
+		tSettings = CircuitBreakerSettings{
+			FailureErrors:       []error{nats.ErrTimeout},
+			FailureThreshold:    2,
+			HalfOpenMaxRequests: 1,
+			OpenTimeout:         time.Minute,
+		}
+		tests = []struct {
+			name  string
+			steps []step
+		}{
+			{
+				name: "Positive Case: Successes keep the circuit closed.",
+				steps: []step{
+					{allow: true, wantState: CIRCUIT_CLOSED},
+					{admission: 0, wantState: CIRCUIT_CLOSED},
+					{allow: true, wantState: CIRCUIT_CLOSED},
+				},
+			},
+			{
+				name: "Positive Case: A success resets the consecutive failures.",
+				steps: []step{
+					{allow: true},
+					{allow: true},
+					{allow: true},
+					{admission: 0, err: nats.ErrTimeout, wantState: CIRCUIT_CLOSED},
+					{admission: 1, wantState: CIRCUIT_CLOSED},
+					{admission: 2, err: nats.ErrTimeout, wantState: CIRCUIT_CLOSED},
+				},
+			},
+			{
+				name: "Positive Case: Other errors aren't failures.",
+				steps: []step{
+					{allow: true},
+					{allow: true},
+					{admission: 0, err: context.Canceled, wantState: CIRCUIT_CLOSED},
+					{admission: 1, err: ErrNotFound, wantState: CIRCUIT_CLOSED},
+				},
+			},
+			{
+				name: "Negative Case: Consecutive failures open the circuit.",
+				steps: []step{
+					{allow: true},
+					{allow: true},
+					{admission: 0, err: nats.ErrTimeout, wantState: CIRCUIT_CLOSED},
+					{admission: 1, err: nats.ErrTimeout, wantState: CIRCUIT_OPEN},
+					{allow: true, wantErr: ErrCircuitOpen, wantState: CIRCUIT_OPEN, wantAfter: 59 * time.Second},
+				},
+			},
+			{
+				name: "Positive Case: A half-open trial success closes the circuit.",
+				steps: []step{
+					{allow: true},
+					{allow: true},
+					{admission: 0, err: nats.ErrTimeout},
+					{admission: 1, err: nats.ErrTimeout, wantState: CIRCUIT_OPEN},
+					{allow: true, expire: true, wantState: CIRCUIT_HALF_OPEN},
+					{allow: true, wantErr: ErrCircuitOpen, wantState: CIRCUIT_HALF_OPEN},
+					{admission: 2, wantState: CIRCUIT_CLOSED},
+					{allow: true, wantState: CIRCUIT_CLOSED},
+				},
+			},
+			{
+				name: "Negative Case: A half-open trial failure opens the circuit again.",
+				steps: []step{
+					{allow: true},
+					{allow: true},
+					{admission: 0, err: nats.ErrTimeout},
+					{admission: 1, err: nats.ErrTimeout, wantState: CIRCUIT_OPEN},
+					{allow: true, expire: true, wantState: CIRCUIT_HALF_OPEN},
+					{admission: 2, err: nats.ErrTimeout, wantState: CIRCUIT_OPEN},
+					{allow: true, wantErr: ErrCircuitOpen, wantState: CIRCUIT_OPEN},
+				},
+			},
+			{
+				name: "Negative Case: A request let through while closed doesn't decide the half-open circuit.",
+				steps: []step{
+					{allow: true},
+					{allow: true},
+					{allow: true},
+					{admission: 1, err: nats.ErrTimeout},
+					{admission: 2, err: nats.ErrTimeout, wantState: CIRCUIT_OPEN},
+					{allow: true, expire: true, wantState: CIRCUIT_HALF_OPEN},
+					{admission: 0, wantState: CIRCUIT_HALF_OPEN},
+					{allow: true, wantErr: ErrCircuitOpen, wantState: CIRCUIT_HALF_OPEN},
+					{admission: 3, wantState: CIRCUIT_CLOSED},
+				},
+			},
+		}
+	)
+
+	for _, ts := range tests {
+		tPtr.Run(
+			ts.name, func(t *testing.T) {
+				var (
+					tBreakersPtr = newCircuitBreakers(tSettings)
+					tCircuitErr  *CircuitOpenError
+					tErrorInfo   pi.ErrorInfo
+					tGeneration  uint64
+					tGenerations []uint64
+					tState       CircuitState
+				)
+
+				for i, st := range ts.steps {
+					if st.expire {
+						tBreakersPtr.breakers["TEST_SUBJECT"].openedAt = time.Now().Add(-tSettings.OpenTimeout)
+					}
+					if st.allow {
+						tGeneration, tErrorInfo = tBreakersPtr.allow("TEST_SUBJECT")
+						tGenerations = append(tGenerations, tGeneration)
+						if errors.Is(tErrorInfo.Error, st.wantErr) == false {
+							t.Fatalf("%v: step %d: got error %v, want %v", ts.name, i, tErrorInfo.Error, st.wantErr)
+						}
+						if errors.As(tErrorInfo.Error, &tCircuitErr) && tCircuitErr.RetryAfter < st.wantAfter {
+							t.Errorf("%v: step %d: got retry after %v, want at least %v", ts.name, i, tCircuitErr.RetryAfter, st.wantAfter)
+						}
+					} else {
+						tBreakersPtr.record("TEST_SUBJECT", tGenerations[st.admission], st.err)
+					}
+					if st.wantState == "" {
+						continue
+					}
+					tBreakersPtr.mutex.Lock()
+					tState = tBreakersPtr.getState("TEST_SUBJECT", time.Now())
+					tBreakersPtr.mutex.Unlock()
+					if tState != st.wantState {
+						t.Fatalf("%v: step %d: got state %v, want %v", ts.name, i, tState, st.wantState)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestNewCircuitBreakersOff(tPtr *testing.T) {
+
+	var (
+		tBreakersPtr = newCircuitBreakers(CircuitBreakerSettings{})
+	)
+
+	if tBreakersPtr != nil {
+		tPtr.Fatalf("got breakers, want nil when FailureThreshold is zero")
+	}
+	if _, tErrorInfo := tBreakersPtr.allow("TEST_SUBJECT"); tErrorInfo.Error != nil {
+		tPtr.Errorf("got error %v from a nil breaker", tErrorInfo.Error)
+	}
+	tBreakersPtr.record("TEST_SUBJECT", 0, nats.ErrTimeout)
+}
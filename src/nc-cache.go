@@ -0,0 +1,306 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	ctv "github.com/sty-holdings/constant-type-vars-go/v2024"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+//goland:noinspection ALL
+const (
+	DEFAULT_CACHE_MAX_ENTRIES = 1000
+)
+
+// ReplyCacheSettings - controls the reply cache for read-only requests. A subject is cached when it has a TTL above zero.
+type ReplyCacheSettings struct {
+	DefaultTTL  time.Duration            // TTL for read-only subjects not in SubjectTTLs. Zero caches only the subjects in SubjectTTLs.
+	MaxBytes    int                      // Total reply data kept. The least recently used replies are removed first. Zero means no limit.
+	MaxEntries  int                      // Replies kept. The least recently used replies are removed first. The default is DEFAULT_CACHE_MAX_ENTRIES.
+	SubjectTTLs map[string]time.Duration // TTL by subject, for example, ctv.SUB_SYNADIA_GET_VERSION. It replaces DefaultTTL.
+}
+
+type cacheEntry struct {
+	expiresAt     time.Time
+	key           string
+	nextPageToken string
+	replyData     []byte
+	subject       string
+}
+
+type replyCache struct {
+	entries   map[string]*list.Element
+	lruList   *list.List
+	mutex     sync.Mutex
+	now       func() time.Time // Returns the time used for the TTLs.
+	settings  ReplyCacheSettings
+	totalSize int
+}
+
+// InvalidateCache - removes the cached replies for the subjects. With no subjects, all cached replies are removed.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) InvalidateCache(subjects ...string) {
+
+	clientPtr.replyCachePtr.invalidate(subjects...)
+}
+
+// newReplyCache - creates the reply cache. Nil is returned when the settings don't give any subject a TTL.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func newReplyCache(settings ReplyCacheSettings) (cachePtr *replyCache) {
+
+	var (
+		tCached = settings.DefaultTTL > 0
+	)
+
+	for _, ttl := range settings.SubjectTTLs {
+		tCached = tCached || ttl > 0
+	}
+	if tCached == false {
+		return
+	}
+	if settings.MaxEntries <= 0 {
+		settings.MaxEntries = DEFAULT_CACHE_MAX_ENTRIES
+	}
+
+	return &replyCache{
+		entries:  make(map[string]*list.Element),
+		lruList:  list.New(),
+		now:      time.Now,
+		settings: settings,
+	}
+}
+
+// getCacheKey - returns the subject and a SHA-256 digest of the marshalled request and the page requested. The digest
+// covers the SaaS key, so callers with different keys don't share replies, but the key itself is never kept in the cache.
+//
+//	Customer Messages: None
+//	Errors: returned from json.Marshal
+//	Verifications: None
+func getCacheKey(subject string, request interface{}, options callOptions) (key string, errorInfo pi.ErrorInfo) {
+
+	var (
+		tDigest      [sha256.Size]byte
+		tRequestData []byte
+	)
+
+	if tRequestData, errorInfo.Error = json.Marshal(request); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, subject))
+		return
+	}
+	tDigest = sha256.Sum256(fmt.Appendf(tRequestData, "\n%d\n%v", options.pageSize, options.pageToken))
+
+	return fmt.Sprintf("%v:%v", subject, hex.EncodeToString(tDigest[:])), errorInfo
+}
+
+// get - returns the cached reply for the key. Expired replies are removed.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (cachePtr *replyCache) get(key string) (entry cacheEntry, ok bool) {
+
+	var (
+		tElementPtr *list.Element
+		tEntryPtr   *cacheEntry
+	)
+
+	cachePtr.mutex.Lock()
+	defer cachePtr.mutex.Unlock()
+
+	if tElementPtr, ok = cachePtr.entries[key]; ok == false {
+		return
+	}
+	tEntryPtr = tElementPtr.Value.(*cacheEntry)
+	if cachePtr.now().Before(tEntryPtr.expiresAt) == false {
+		cachePtr.remove(tElementPtr)
+		return cacheEntry{}, false
+	}
+	cachePtr.lruList.MoveToFront(tElementPtr)
+
+	return *tEntryPtr, true
+}
+
+// getTTL - returns how long replies on the subject are cached. Zero means the subject isn't cached.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (cachePtr *replyCache) getTTL(subject string) time.Duration {
+
+	if ttl, ok := cachePtr.settings.SubjectTTLs[subject]; ok {
+		return ttl
+	}
+
+	return cachePtr.settings.DefaultTTL
+}
+
+// intercept - returns cached replies for read-only requests and caches successful replies. WithCallCacheBypass skips the
+// lookup, and the new reply replaces the cached one. Safe to call on a nil cache.
+//
+//	Customer Messages: None
+//	Errors: returned from getCacheKey, json.Unmarshal, next
+//	Verifications: None
+func (cachePtr *replyCache) intercept(ctx context.Context, callPtr *Call, next Invoker) (errorInfo pi.ErrorInfo) {
+
+	var (
+		ok     bool
+		tEntry cacheEntry
+		tKey   string
+		tTTL   time.Duration
+	)
+
+	if cachePtr == nil || callPtr.ReadOnly == false {
+		return next(ctx, callPtr)
+	}
+	if tTTL = cachePtr.getTTL(callPtr.Subject); tTTL <= 0 {
+		return next(ctx, callPtr)
+	}
+	if tKey, errorInfo = getCacheKey(callPtr.Subject, callPtr.Request, callPtr.options); errorInfo.Error != nil {
+		return
+	}
+
+	if callPtr.options.cacheBypass == false {
+		if tEntry, ok = cachePtr.get(tKey); ok {
+			if errorInfo.Error = json.Unmarshal(tEntry.replyData, callPtr.ReplyPtr); errorInfo.Error != nil {
+				errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, callPtr.Subject))
+				return
+			}
+			callPtr.NextPageToken = tEntry.nextPageToken
+			callPtr.ReplyData = tEntry.replyData
+			if callPtr.options.replyInfoPtr != nil {
+				callPtr.options.replyInfoPtr.Cached = true
+			}
+			return
+		}
+	}
+
+	if errorInfo = next(ctx, callPtr); errorInfo.Error == nil && len(callPtr.ReplyData) > 0 {
+		cachePtr.set(tKey, callPtr.Subject, callPtr.ReplyData, callPtr.NextPageToken, tTTL)
+	}
+
+	return
+}
+
+// invalidate - removes the cached replies for the subjects, or all cached replies when there are no subjects. Safe to call
+// on a nil cache.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (cachePtr *replyCache) invalidate(subjects ...string) {
+
+	var (
+		tElementPtr *list.Element
+		tNextPtr    *list.Element
+		tSubjects   = make(map[string]bool, len(subjects))
+	)
+
+	if cachePtr == nil {
+		return
+	}
+
+	for _, subject := range subjects {
+		tSubjects[subject] = true
+	}
+
+	cachePtr.mutex.Lock()
+	defer cachePtr.mutex.Unlock()
+
+	for tElementPtr = cachePtr.lruList.Front(); tElementPtr != nil; tElementPtr = tNextPtr {
+		tNextPtr = tElementPtr.Next()
+		if len(tSubjects) == 0 || tSubjects[tElementPtr.Value.(*cacheEntry).subject] {
+			cachePtr.remove(tElementPtr)
+		}
+	}
+}
+
+// remove - removes the entry from the cache. The caller must hold the mutex.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (cachePtr *replyCache) remove(elementPtr *list.Element) {
+
+	var (
+		tEntryPtr = elementPtr.Value.(*cacheEntry)
+	)
+
+	cachePtr.lruList.Remove(elementPtr)
+	delete(cachePtr.entries, tEntryPtr.key)
+	cachePtr.totalSize -= len(tEntryPtr.replyData)
+}
+
+// set - caches the reply data and next page token for the TTL, then removes the least recently used replies until the cache is within MaxEntries
+// and MaxBytes. A reply larger than MaxBytes isn't cached.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (cachePtr *replyCache) set(key, subject string, replyData []byte, nextPageToken string, ttl time.Duration) {
+
+	var (
+		tElementPtr *list.Element
+		ok          bool
+	)
+
+	if cachePtr.settings.MaxBytes > 0 && len(replyData) > cachePtr.settings.MaxBytes {
+		return
+	}
+
+	cachePtr.mutex.Lock()
+	defer cachePtr.mutex.Unlock()
+
+	if tElementPtr, ok = cachePtr.entries[key]; ok {
+		cachePtr.remove(tElementPtr)
+	}
+	cachePtr.entries[key] = cachePtr.lruList.PushFront(
+		&cacheEntry{
+			expiresAt:     cachePtr.now().Add(ttl),
+			key:           key,
+			nextPageToken: nextPageToken,
+			replyData:     replyData,
+			subject:       subject,
+		},
+	)
+	cachePtr.totalSize += len(replyData)
+
+	for cachePtr.lruList.Len() > cachePtr.settings.MaxEntries || (cachePtr.settings.MaxBytes > 0 && cachePtr.totalSize > cachePtr.settings.MaxBytes) {
+		cachePtr.remove(cachePtr.lruList.Back())
+	}
+}
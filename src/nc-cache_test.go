@@ -0,0 +1,183 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestReplyCache(tPtr *testing.T) {
+
+	type step struct {
+		advance time.Duration // Move the cache clock forward before the step.
+		get     bool          // Call get, otherwise set.
+		key     string        // Cache key.
+		size    int           // Reply data size for set.
+		ttl     time.Duration // TTL for set. The default is a minute.
+		wantHit bool          // What get returns.
+	}
+
+	var (
+		tests = []struct {
+			name      string
+			settings  ReplyCacheSettings
+			steps     []step
+			wantBytes int
+		}{
+			{
+				name:     "Positive Case: A cached reply is returned.",
+				settings: ReplyCacheSettings{DefaultTTL: time.Minute},
+				steps: []step{
+					{key: "A", size: 10},
+					{get: true, key: "A", wantHit: true},
+					{get: true, key: "B"},
+				},
+				wantBytes: 10,
+			},
+			{
+				name:     "Negative Case: An expired reply is removed.",
+				settings: ReplyCacheSettings{DefaultTTL: time.Minute},
+				steps: []step{
+					{key: "A", size: 10, ttl: time.Second},
+					{get: true, key: "A", wantHit: true},
+					{advance: time.Second, get: true, key: "A"},
+				},
+			},
+			{
+				name:     "Positive Case: MaxEntries removes the least recently used reply.",
+				settings: ReplyCacheSettings{DefaultTTL: time.Minute, MaxEntries: 2},
+				steps: []step{
+					{key: "A", size: 1},
+					{key: "B", size: 1},
+					{get: true, key: "A", wantHit: true},
+					{key: "C", size: 1},
+					{get: true, key: "B"},
+					{get: true, key: "A", wantHit: true},
+					{get: true, key: "C", wantHit: true},
+				},
+				wantBytes: 2,
+			},
+			{
+				name:     "Positive Case: MaxBytes removes the least recently used replies.",
+				settings: ReplyCacheSettings{DefaultTTL: time.Minute, MaxBytes: 10},
+				steps: []step{
+					{key: "A", size: 4},
+					{key: "B", size: 4},
+					{key: "C", size: 4},
+					{get: true, key: "A"},
+					{get: true, key: "B", wantHit: true},
+					{get: true, key: "C", wantHit: true},
+				},
+				wantBytes: 8,
+			},
+			{
+				name:     "Negative Case: A reply larger than MaxBytes isn't cached.",
+				settings: ReplyCacheSettings{DefaultTTL: time.Minute, MaxBytes: 10},
+				steps: []step{
+					{key: "A", size: 4},
+					{key: "B", size: 11},
+					{get: true, key: "A", wantHit: true},
+					{get: true, key: "B"},
+				},
+				wantBytes: 4,
+			},
+			{
+				name:     "Positive Case: Replacing a reply updates the size.",
+				settings: ReplyCacheSettings{DefaultTTL: time.Minute},
+				steps: []step{
+					{key: "A", size: 4},
+					{key: "A", size: 6},
+					{get: true, key: "A", wantHit: true},
+				},
+				wantBytes: 6,
+			},
+		}
+	)
+
+	for _, ts := range tests {
+		tPtr.Run(
+			ts.name, func(t *testing.T) {
+				var (
+					tCachePtr = newReplyCache(ts.settings)
+					tNow      = time.Now()
+				)
+
+				tCachePtr.now = func() time.Time { return tNow }
+				for i, st := range ts.steps {
+					tNow = tNow.Add(st.advance)
+					if st.get == false {
+						if st.ttl == 0 {
+							st.ttl = time.Minute
+						}
+						tCachePtr.set(st.key, "TEST_SUBJECT", bytes.Repeat([]byte("x"), st.size), "", st.ttl)
+						continue
+					}
+					if _, ok := tCachePtr.get(st.key); ok != st.wantHit {
+						t.Fatalf("%v: step %d: got hit %v for %v, want %v", ts.name, i, ok, st.key, st.wantHit)
+					}
+				}
+				if tCachePtr.totalSize != ts.wantBytes {
+					t.Errorf("%v: got %d bytes, want %d", ts.name, tCachePtr.totalSize, ts.wantBytes)
+				}
+			},
+		)
+	}
+}
+
+func TestReplyCacheInvalidate(tPtr *testing.T) {
+
+	var (
+		tCachePtr = newReplyCache(ReplyCacheSettings{DefaultTTL: time.Minute})
+	)
+
+	tCachePtr.set("A", "SUBJECT_A", []byte("a"), "", time.Minute)
+	tCachePtr.set("B", "SUBJECT_B", []byte("b"), "", time.Minute)
+
+	tCachePtr.invalidate("SUBJECT_A")
+	if _, ok := tCachePtr.get("A"); ok {
+		tPtr.Errorf("got a reply for SUBJECT_A after it was invalidated")
+	}
+	if _, ok := tCachePtr.get("B"); ok == false {
+		tPtr.Errorf("got no reply for SUBJECT_B, want it kept")
+	}
+	tCachePtr.invalidate()
+	if len(tCachePtr.entries) != 0 || tCachePtr.lruList.Len() != 0 || tCachePtr.totalSize != 0 {
+		tPtr.Errorf("got %d entries and %d bytes, want an empty cache", len(tCachePtr.entries), tCachePtr.totalSize)
+	}
+}
+
+func TestNewReplyCache(tPtr *testing.T) {
+
+	if newReplyCache(ReplyCacheSettings{DefaultTTL: time.Second}) == nil {
+		tPtr.Errorf("got nil with a default TTL, want a cache")
+	}
+	if newReplyCache(ReplyCacheSettings{SubjectTTLs: map[string]time.Duration{"TEST_SUBJECT": time.Second}}) == nil {
+		tPtr.Errorf("got nil with a subject TTL, want a cache")
+	}
+	if tCachePtr := newReplyCache(ReplyCacheSettings{MaxEntries: 10}); tCachePtr != nil {
+		tPtr.Errorf("got %v without a TTL, want nil", tCachePtr)
+	}
+}
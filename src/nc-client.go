@@ -27,9 +27,13 @@
 package src
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
 
-	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/trace"
 
 	ctv "github.com/sty-holdings/constant-type-vars-go/v2024"
 	ncs "github.com/sty-holdings/nats-connect-shared/v2024"
@@ -39,15 +43,32 @@ import (
 )
 
 //goland:noinspection ALL
-const ()
+const (
+	DRAIN_POLL_INTERVAL = 10 * time.Millisecond
+)
 
 type NCClient struct {
-	awsSettings        awss.AWSSettings
-	environment        string
-	natsService        ns.NATSService
-	natsConfig         ns.NATSConfiguration
-	styhCustomerConfig styhCustomerConfig
-	tempDirectory      string
+	awsSettings         awss.AWSSettings
+	circuitBreakersPtr  *circuitBreakers
+	closeMutex          sync.RWMutex
+	closing             bool
+	compression         CompressionSettings
+	environment         string
+	inFlight            sync.WaitGroup
+	interceptors        []Interceptor
+	loggerPtr           *slog.Logger
+	metricsCollectorPtr *MetricsCollector
+	natsService         ns.NATSService
+	rateLimiterPtr      *rateLimiter
+	replyCachePtr       *replyCache
+	replyEnvelope       ReplyEnvelopeSettings
+	requestTimeout      time.Duration
+	retryPolicy         RetryPolicy
+	styhCustomerConfig  styhCustomerConfig
+	subjectTimeouts     map[string]time.Duration
+	tempDirectory       string
+	tokenRefresherPtr   *tokenRefresher
+	tracer              trace.Tracer
 }
 
 type SaaSKeysTokens struct {
@@ -56,387 +77,410 @@ type SaaSKeysTokens struct {
 	SynadiaToken string `json:"synadia_token"`
 }
 
-// SynaidaGetPersonalAccessToken - will provide information about your token
-func (clientPtr *NCClient) SynaidaGetPersonalAccessToken(request interface{}) (reply ncs.GetPersonalAccessTokenReply, errorInfo pi.ErrorInfo) {
+// Close - rejects new requests with ErrClientClosed, waits for the requests already sent to finish, stops the token refresh,
+// drains the NATS connection, closes it, and securely deletes the NATS credentials and TLS files written to the temporary
+// directory. If the context is done before the requests finish or the drain completes, the connection is closed immediately.
+// Calling Close more than once is safe.
+//
+//	Customer Messages: None
+//	Errors: ctx.Err, returned from Drain, removeTemporaryFiles
+//	Verifications: None
+func (clientPtr *NCClient) Close(ctx context.Context) (errorInfo pi.ErrorInfo) {
 
 	var (
-		tReply *nats.Msg
+		tDraining = true
+		tTicker   *time.Ticker
 	)
 
-	if tReply, errorInfo = getPersonalAccessToken(
-		clientPtr.styhCustomerConfig.clientId,
-		clientPtr.styhCustomerConfig.secretKey,
-		clientPtr.styhCustomerConfig.username,
-		clientPtr.natsService.InstanceName,
-		request.(ncs.GetPersonalAccessTokenRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
-		return
+	clientPtr.closeMutex.Lock()
+	clientPtr.closing = true
+	clientPtr.closeMutex.Unlock()
+
+	if errorInfo.Error = clientPtr.waitForRequests(ctx); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, clientPtr.natsService.InstanceName)
+	}
+
+	if clientPtr.tokenRefresherPtr != nil {
+		clientPtr.tokenRefresherPtr.stop()
+	}
+
+	if clientPtr.natsService.ConnPtr != nil && clientPtr.natsService.ConnPtr.IsClosed() == false && errorInfo.Error == nil {
+		if errorInfo.Error = clientPtr.natsService.ConnPtr.Drain(); errorInfo.Error != nil {
+			errorInfo = pi.NewErrorInfo(errorInfo.Error, clientPtr.natsService.InstanceName)
+		} else {
+			tTicker = time.NewTicker(DRAIN_POLL_INTERVAL)
+			for tDraining && clientPtr.natsService.ConnPtr.IsClosed() == false {
+				select {
+				case <-ctx.Done():
+					errorInfo = pi.NewErrorInfo(ctx.Err(), clientPtr.natsService.InstanceName)
+					tDraining = false
+				case <-tTicker.C:
+				}
+			}
+			tTicker.Stop()
+		}
+	}
+	if clientPtr.natsService.ConnPtr != nil {
+		clientPtr.natsService.ConnPtr.Close() // Closing an already closed connection is a no-op.
 	}
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
+	if tErrorInfo := removeTemporaryFiles(clientPtr.tempDirectory); tErrorInfo.Error != nil && errorInfo.Error == nil {
+		errorInfo = tErrorInfo
 	}
 
 	return
 }
 
-// SynaidaGetSystem - will provide information about the system
-func (clientPtr *NCClient) SynaidaGetSystem(request interface{}) (reply ncs.GetSystemReply, errorInfo pi.ErrorInfo) {
+// Do - sends the request on the endpoint's subject and returns the decoded reply, for example,
+// Do(ctx, clientPtr, SynaidaGetTeamEndpoint, request). The endpoint sets the request and reply types, so a request of another
+// type doesn't compile.
+//
+//	Customer Messages: None
+//	Errors: ErrEndpointNotRegistered, returned from dispatch
+//	Verifications: None
+func Do[Request any, Reply any](
+	ctx context.Context,
+	clientPtr *NCClient,
+	requestEndpoint Endpoint[Request, Reply],
+	request Request,
+	callOptions ...CallOption,
+) (
+	reply Reply,
+	errorInfo pi.ErrorInfo,
+) {
+
+	errorInfo = clientPtr.dispatch(ctx, requestEndpoint.endpoint, request, &reply, callOptions)
 
-	var (
-		tReply *nats.Msg
-	)
+	return
+}
 
-	if tReply, errorInfo = getSystem(
-		clientPtr.styhCustomerConfig.clientId,
-		clientPtr.styhCustomerConfig.secretKey,
-		clientPtr.styhCustomerConfig.username,
-		clientPtr.natsService.InstanceName,
-		request.(ncs.GetSystemRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
-		return
-	}
+// SynaidaGetPersonalAccessToken - will provide information about your token
+func (clientPtr *NCClient) SynaidaGetPersonalAccessToken(request ncs.GetPersonalAccessTokenRequest) (reply ncs.GetPersonalAccessTokenReply, errorInfo pi.ErrorInfo) {
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
-	}
+	return clientPtr.SynaidaGetPersonalAccessTokenCtx(context.Background(), request)
+}
 
-	return
+// SynaidaGetPersonalAccessTokenCtx - is SynaidaGetPersonalAccessToken with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaGetPersonalAccessTokenCtx(ctx context.Context, request ncs.GetPersonalAccessTokenRequest, callOptions ...CallOption) (reply ncs.GetPersonalAccessTokenReply, errorInfo pi.ErrorInfo) {
+
+	return Do(ctx, clientPtr, SynaidaGetPersonalAccessTokenEndpoint, request, callOptions...)
 }
 
-// SynaidaGetSystemLimits - will provide information about the system limits
-func (clientPtr *NCClient) SynaidaGetSystemLimits(request interface{}) (reply ncs.GetSystemLimitsReply, errorInfo pi.ErrorInfo) {
+// SynaidaGetSystem - will provide information about the system
+func (clientPtr *NCClient) SynaidaGetSystem(request ncs.GetSystemRequest) (reply ncs.GetSystemReply, errorInfo pi.ErrorInfo) {
 
-	var (
-		tReply *nats.Msg
-	)
+	return clientPtr.SynaidaGetSystemCtx(context.Background(), request)
+}
 
-	if tReply, errorInfo = getSystemLimits(
-		clientPtr.styhCustomerConfig.clientId,
-		clientPtr.styhCustomerConfig.secretKey,
-		clientPtr.styhCustomerConfig.username,
-		clientPtr.natsService.InstanceName,
-		request.(ncs.GetSystemLimitsRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
-		return
-	}
+// SynaidaGetSystemCtx - is SynaidaGetSystem with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaGetSystemCtx(ctx context.Context, request ncs.GetSystemRequest, callOptions ...CallOption) (reply ncs.GetSystemReply, errorInfo pi.ErrorInfo) {
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
-	}
+	return Do(ctx, clientPtr, SynaidaGetSystemEndpoint, request, callOptions...)
+}
 
-	return
+// SynaidaGetSystemLimits - will provide information about the system limits
+func (clientPtr *NCClient) SynaidaGetSystemLimits(request ncs.GetSystemLimitsRequest) (reply ncs.GetSystemLimitsReply, errorInfo pi.ErrorInfo) {
+
+	return clientPtr.SynaidaGetSystemLimitsCtx(context.Background(), request)
 }
 
-// SynaidaGetTeam - will provide information about the team
-func (clientPtr *NCClient) SynaidaGetTeam(request interface{}) (reply ncs.GetTeamReply, errorInfo pi.ErrorInfo) {
+// SynaidaGetSystemLimitsCtx - is SynaidaGetSystemLimits with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaGetSystemLimitsCtx(ctx context.Context, request ncs.GetSystemLimitsRequest, callOptions ...CallOption) (reply ncs.GetSystemLimitsReply, errorInfo pi.ErrorInfo) {
 
-	var (
-		tReply *nats.Msg
-	)
+	return Do(ctx, clientPtr, SynaidaGetSystemLimitsEndpoint, request, callOptions...)
+}
 
-	if tReply, errorInfo = getTeam(
-		clientPtr.styhCustomerConfig.clientId,
-		clientPtr.styhCustomerConfig.secretKey,
-		clientPtr.styhCustomerConfig.username,
-		clientPtr.natsService.InstanceName,
-		request.(ncs.GetTeamRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
-		return
-	}
+// SynaidaGetTeam - will provide information about the team
+func (clientPtr *NCClient) SynaidaGetTeam(request ncs.GetTeamRequest) (reply ncs.GetTeamReply, errorInfo pi.ErrorInfo) {
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
-	}
+	return clientPtr.SynaidaGetTeamCtx(context.Background(), request)
+}
 
-	return
+// SynaidaGetTeamCtx - is SynaidaGetTeam with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaGetTeamCtx(ctx context.Context, request ncs.GetTeamRequest, callOptions ...CallOption) (reply ncs.GetTeamReply, errorInfo pi.ErrorInfo) {
+
+	return Do(ctx, clientPtr, SynaidaGetTeamEndpoint, request, callOptions...)
 }
 
 // SynaidaGetTeamLimits - will provide information about the team's limits
-func (clientPtr *NCClient) SynaidaGetTeamLimits(request interface{}) (reply ncs.GetTeamLimitsReply, errorInfo pi.ErrorInfo) {
-
-	var (
-		tReply *nats.Msg
-	)
+func (clientPtr *NCClient) SynaidaGetTeamLimits(request ncs.GetTeamLimitsRequest) (reply ncs.GetTeamLimitsReply, errorInfo pi.ErrorInfo) {
 
-	if tReply, errorInfo = getTeamLimits(
-		clientPtr.styhCustomerConfig.clientId,
-		clientPtr.styhCustomerConfig.secretKey,
-		clientPtr.styhCustomerConfig.username,
-		clientPtr.natsService.InstanceName,
-		request.(ncs.GetTeamLimitsRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
-		return
-	}
+	return clientPtr.SynaidaGetTeamLimitsCtx(context.Background(), request)
+}
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
-	}
+// SynaidaGetTeamLimitsCtx - is SynaidaGetTeamLimits with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaGetTeamLimitsCtx(ctx context.Context, request ncs.GetTeamLimitsRequest, callOptions ...CallOption) (reply ncs.GetTeamLimitsReply, errorInfo pi.ErrorInfo) {
 
-	return
+	return Do(ctx, clientPtr, SynaidaGetTeamLimitsEndpoint, request, callOptions...)
 }
 
 // SynaidaGetVersion - will provide the version information
-func (clientPtr *NCClient) SynaidaGetVersion(request interface{}) (reply ncs.GetVersionReply, errorInfo pi.ErrorInfo) {
+func (clientPtr *NCClient) SynaidaGetVersion(request ncs.GetVersionRequest) (reply ncs.GetVersionReply, errorInfo pi.ErrorInfo) {
 
-	var (
-		tReply *nats.Msg
-	)
-
-	if tReply, errorInfo = getVersion(
-		clientPtr.styhCustomerConfig.clientId,
-		clientPtr.styhCustomerConfig.secretKey,
-		clientPtr.styhCustomerConfig.username,
-		clientPtr.natsService.InstanceName,
-		request.(ncs.GetVersionRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
-		return
-	}
+	return clientPtr.SynaidaGetVersionCtx(context.Background(), request)
+}
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
-	}
+// SynaidaGetVersionCtx - is SynaidaGetVersion with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaGetVersionCtx(ctx context.Context, request ncs.GetVersionRequest, callOptions ...CallOption) (reply ncs.GetVersionReply, errorInfo pi.ErrorInfo) {
 
-	return
+	return Do(ctx, clientPtr, SynaidaGetVersionEndpoint, request, callOptions...)
 }
 
 // SynaidaListAccounts - will list the account for a system id
-func (clientPtr *NCClient) SynaidaListAccounts(request interface{}) (reply ncs.ListAccountsReply, errorInfo pi.ErrorInfo) {
+func (clientPtr *NCClient) SynaidaListAccounts(request ncs.ListAccountsRequest) (reply ncs.ListAccountsReply, errorInfo pi.ErrorInfo) {
 
-	var (
-		tReply *nats.Msg
-	)
+	return clientPtr.SynaidaListAccountsCtx(context.Background(), request)
+}
 
-	if tReply, errorInfo = listAccounts(
-		clientPtr.styhCustomerConfig.clientId,
-		clientPtr.styhCustomerConfig.secretKey,
-		clientPtr.styhCustomerConfig.username,
-		clientPtr.natsService.InstanceName,
-		request.(ncs.ListAccountsRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
-		return
-	}
+// SynaidaListAccountsCtx - is SynaidaListAccounts with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaListAccountsCtx(ctx context.Context, request ncs.ListAccountsRequest, callOptions ...CallOption) (reply ncs.ListAccountsReply, errorInfo pi.ErrorInfo) {
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
-	}
-
-	return
+	return Do(ctx, clientPtr, SynaidaListAccountsEndpoint, request, callOptions...)
 }
 
 // SynaidaListInfoAppUsersTeam - will list the user account for a team id
-func (clientPtr *NCClient) SynaidaListInfoAppUsersTeam(request interface{}) (reply ncs.ListInfoAppUsersTeamReply, errorInfo pi.ErrorInfo) {
+func (clientPtr *NCClient) SynaidaListInfoAppUsersTeam(request ncs.ListInfoAppUserTeamRequest) (reply ncs.ListInfoAppUsersTeamReply, errorInfo pi.ErrorInfo) {
 
-	var (
-		tReply *nats.Msg
-	)
+	return clientPtr.SynaidaListInfoAppUsersTeamCtx(context.Background(), request)
+}
 
-	if tReply, errorInfo = listInfoAppUsersTeam(
-		clientPtr.styhCustomerConfig.clientId,
-		clientPtr.styhCustomerConfig.secretKey,
-		clientPtr.styhCustomerConfig.username,
-		clientPtr.natsService.InstanceName,
-		request.(ncs.ListInfoAppUserTeamRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
-		return
-	}
+// SynaidaListInfoAppUsersTeamCtx - is SynaidaListInfoAppUsersTeam with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaListInfoAppUsersTeamCtx(ctx context.Context, request ncs.ListInfoAppUserTeamRequest, callOptions ...CallOption) (reply ncs.ListInfoAppUsersTeamReply, errorInfo pi.ErrorInfo) {
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
-	}
+	return Do(ctx, clientPtr, SynaidaListInfoAppUsersTeamEndpoint, request, callOptions...)
+}
 
-	return
+func (clientPtr *NCClient) SynaidaListNATSUsers(request ncs.ListNATSUsersRequest) (reply ncs.ListNATSUsersReply, errorInfo pi.ErrorInfo) {
+
+	return clientPtr.SynaidaListNATSUsersCtx(context.Background(), request)
 }
 
-func (clientPtr *NCClient) SynaidaListNATSUsers(request interface{}) (reply ncs.ListNATSUsersReply, errorInfo pi.ErrorInfo) {
+// SynaidaListNATSUsersCtx - is SynaidaListNATSUsers with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaListNATSUsersCtx(ctx context.Context, request ncs.ListNATSUsersRequest, callOptions ...CallOption) (reply ncs.ListNATSUsersReply, errorInfo pi.ErrorInfo) {
 
-	var (
-		tReply *nats.Msg
-	)
+	return Do(ctx, clientPtr, SynaidaListNATSUsersEndpoint, request, callOptions...)
+}
 
-	if tReply, errorInfo = listNATSUsers(
-		clientPtr.styhCustomerConfig.clientId, clientPtr.styhCustomerConfig.secretKey, clientPtr.styhCustomerConfig.username, clientPtr.natsService.InstanceName,
-		request.(ncs.ListNATSUsersRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
-		return
-	}
+// SynaidaListPersonalAccessTokens - will list your personal access tokens
+func (clientPtr *NCClient) SynaidaListPersonalAccessTokens(request ncs.ListPersonalAccessTokensRequest) (reply ncs.ListPersonalAccessTokensReply, errorInfo pi.ErrorInfo) {
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
-	}
+	return clientPtr.SynaidaListPersonalAccessTokensCtx(context.Background(), request)
+}
 
-	return
+// SynaidaListPersonalAccessTokensCtx - is SynaidaListPersonalAccessTokens with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaListPersonalAccessTokensCtx(ctx context.Context, request ncs.ListPersonalAccessTokensRequest, callOptions ...CallOption) (reply ncs.ListPersonalAccessTokensReply, errorInfo pi.ErrorInfo) {
+
+	return Do(ctx, clientPtr, SynaidaListPersonalAccessTokensEndpoint, request, callOptions...)
 }
 
-// SynaidaListPersonalAccessTokens - will list your personal access tokens
-func (clientPtr *NCClient) SynaidaListPersonalAccessTokens(request interface{}) (reply ncs.ListPersonalAccessTokensReply, errorInfo pi.ErrorInfo) {
+// SynaidaListSystems - will list systems for a team
+func (clientPtr *NCClient) SynaidaListSystems(request ncs.ListSystemsRequest) (reply ncs.ListSystemsReply, errorInfo pi.ErrorInfo) {
 
-	var (
-		tReply *nats.Msg
-	)
+	return clientPtr.SynaidaListSystemsCtx(context.Background(), request)
+}
 
-	if tReply, errorInfo = listPersonalAccessTokens(
-		clientPtr.styhCustomerConfig.clientId,
-		clientPtr.styhCustomerConfig.secretKey,
-		clientPtr.styhCustomerConfig.username,
-		clientPtr.natsService.InstanceName,
-		request.(ncs.ListPersonalAccessTokensRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
-		return
-	}
+// SynaidaListSystemsCtx - is SynaidaListSystems with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaListSystemsCtx(ctx context.Context, request ncs.ListSystemsRequest, callOptions ...CallOption) (reply ncs.ListSystemsReply, errorInfo pi.ErrorInfo) {
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
-	}
+	return Do(ctx, clientPtr, SynaidaListSystemsEndpoint, request, callOptions...)
+}
 
-	return
+// SynaidaListSystemAccountInfo - will list system account info
+func (clientPtr *NCClient) SynaidaListSystemAccountInfo(request ncs.ListSystemAccountInfoRequest) (reply ncs.ListSystemAccountInfoReply, errorInfo pi.ErrorInfo) {
+
+	return clientPtr.SynaidaListSystemAccountInfoCtx(context.Background(), request)
 }
 
-// SynaidaListSystems - will list systems for a team
-func (clientPtr *NCClient) SynaidaListSystems(request interface{}) (reply ncs.ListSystemsReply, errorInfo pi.ErrorInfo) {
+// SynaidaListSystemAccountInfoCtx - is SynaidaListSystemAccountInfo with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaListSystemAccountInfoCtx(ctx context.Context, request ncs.ListSystemAccountInfoRequest, callOptions ...CallOption) (reply ncs.ListSystemAccountInfoReply, errorInfo pi.ErrorInfo) {
 
-	var (
-		tReply *nats.Msg
-	)
+	return Do(ctx, clientPtr, SynaidaListSystemAccountInfoEndpoint, request, callOptions...)
+}
 
-	if tReply, errorInfo = listSystems(
-		clientPtr.styhCustomerConfig.clientId,
-		clientPtr.styhCustomerConfig.secretKey,
-		clientPtr.styhCustomerConfig.username,
-		clientPtr.natsService.InstanceName,
-		request.(ncs.ListSystemsRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
-		return
-	}
+func (clientPtr *NCClient) SynaidaListSystemServerInfo(request ncs.ListSystemServerInfoRequest) (reply ncs.ListSystemServerInfoReply, errorInfo pi.ErrorInfo) {
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
-	}
+	return clientPtr.SynaidaListSystemServerInfoCtx(context.Background(), request)
+}
 
-	return
+// SynaidaListSystemServerInfoCtx - is SynaidaListSystemServerInfo with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaListSystemServerInfoCtx(ctx context.Context, request ncs.ListSystemServerInfoRequest, callOptions ...CallOption) (reply ncs.ListSystemServerInfoReply, errorInfo pi.ErrorInfo) {
+
+	return Do(ctx, clientPtr, SynaidaListSystemServerInfoEndpoint, request, callOptions...)
 }
 
-// SynaidaListSystemAccountInfo - will list system account info
-func (clientPtr *NCClient) SynaidaListSystemAccountInfo(request interface{}) (reply ncs.ListSystemAccountInfoReply, errorInfo pi.ErrorInfo) {
+// SynaidaListTeamServerAccounts - will list all service accounts for the team
+// This appears to be a restricted API. Only tested using a personal account.
+func (clientPtr *NCClient) SynaidaListTeamServerAccounts(request ncs.ListTeamServerAccountsRequest) (reply ncs.ListTeamServerAccountsReply, errorInfo pi.ErrorInfo) {
 
-	var (
-		tReply *nats.Msg
-	)
+	return clientPtr.SynaidaListTeamServerAccountsCtx(context.Background(), request)
+}
 
-	if tReply, errorInfo = listSystemAccountInfo(
-		clientPtr.styhCustomerConfig.clientId,
-		clientPtr.styhCustomerConfig.secretKey,
-		clientPtr.styhCustomerConfig.username,
-		clientPtr.natsService.InstanceName,
-		request.(ncs.ListSystemAccountInfoRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
-		return
-	}
+// SynaidaListTeamServerAccountsCtx - is SynaidaListTeamServerAccounts with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaListTeamServerAccountsCtx(ctx context.Context, request ncs.ListTeamServerAccountsRequest, callOptions ...CallOption) (reply ncs.ListTeamServerAccountsReply, errorInfo pi.ErrorInfo) {
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
-	}
+	return Do(ctx, clientPtr, SynaidaListTeamServerAccountsEndpoint, request, callOptions...)
+}
 
-	return
+// SynaidaListTeams - returns information about all your teams
+func (clientPtr *NCClient) SynaidaListTeams(request ncs.ListTeamsRequest) (reply ncs.ListTeamsReply, errorInfo pi.ErrorInfo) {
+
+	return clientPtr.SynaidaListTeamsCtx(context.Background(), request)
 }
 
-func (clientPtr *NCClient) SynaidaListSystemServerInfo(request interface{}) (reply ncs.ListSystemServerInfoReply, errorInfo pi.ErrorInfo) {
+// SynaidaListTeamsCtx - is SynaidaListTeams with a context. The context deadline and cancellation are passed to the NATS request.
+// The call options apply to this request only.
+func (clientPtr *NCClient) SynaidaListTeamsCtx(ctx context.Context, request ncs.ListTeamsRequest, callOptions ...CallOption) (reply ncs.ListTeamsReply, errorInfo pi.ErrorInfo) {
+
+	return Do(ctx, clientPtr, SynaidaListTeamsEndpoint, request, callOptions...)
+}
+
+// dispatch - sends the request on the endpoint's subject and decodes the reply into replyPtr. The request passes
+// through the interceptor chain returned by newInvoker. Every attempt carries the same request id, which is added to the
+// returned ErrorInfo. The request is traced in one span, and the trace context is sent with each attempt.
+//
+//	Customer Messages: None
+//	Errors: ErrEndpointNotRegistered, ErrClientClosed, returned from the interceptor chain
+//	Verifications: None
+func (clientPtr *NCClient) dispatch(
+	ctx context.Context,
+	requestEndpoint endpoint,
+	request interface{},
+	replyPtr interface{},
+	callOptions []CallOption,
+) (
+	errorInfo pi.ErrorInfo,
+) {
 
 	var (
-		tReply *nats.Msg
+		tCallPtr   *Call
+		tOptions   = newCallOptions(callOptions...)
+		tRequestId = newRequestId(tOptions.requestId)
+		tSpan      trace.Span
 	)
 
-	if tReply, errorInfo = listSystemServerInfo(
-		clientPtr.styhCustomerConfig.clientId,
-		clientPtr.styhCustomerConfig.secretKey,
-		clientPtr.styhCustomerConfig.username,
-		clientPtr.natsService.InstanceName,
-		request.(ncs.ListSystemServerInfoRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if tOptions.replyInfoPtr != nil {
+		*tOptions.replyInfoPtr = ReplyInfo{RequestId: tRequestId}
+	}
+
+	if requestEndpoint.subject == ctv.VAL_EMPTY {
+		errorInfo = pi.NewErrorInfo(ErrEndpointNotRegistered, fmt.Sprintf("%v%T%v%v", TXT_REQUEST_TYPE, request, TXT_REQUEST_ID, tRequestId))
 		return
 	}
+	if clientPtr.startRequest() == false {
+		errorInfo = pi.NewErrorInfo(ErrClientClosed, fmt.Sprintf("%v%v%v%v", ctv.TXT_SUBJECT, requestEndpoint.subject, TXT_REQUEST_ID, tRequestId))
+		return
+	}
+	defer clientPtr.inFlight.Done()
+
+	tCallPtr = &Call{
+		ReadOnly:  requestEndpoint.readOnly,
+		ReplyPtr:  replyPtr,
+		Request:   request,
+		RequestId: tRequestId,
+		Subject:   requestEndpoint.subject,
+		options:   tOptions,
+	}
+
+	ctx, tSpan = clientPtr.startSpan(ctx, requestEndpoint.subject, tRequestId)
+	defer func() {
+		endSpan(tSpan, tCallPtr.Attempts, errorInfo)
+	}()
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
+	if errorInfo = clientPtr.newInvoker()(ctx, tCallPtr); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v%v", errorInfo.AdditionalInfo, TXT_REQUEST_ID, tRequestId))
+		return
+	}
+	if tOptions.replyInfoPtr != nil {
+		tOptions.replyInfoPtr.NextPageToken = tCallPtr.NextPageToken
 	}
 
 	return
 }
 
-// SynaidaListTeamServerAccounts - will list all service accounts for the team
-// This appears to be a restricted API. Only tested using a personal account.
-func (clientPtr *NCClient) SynaidaListTeamServerAccounts(request interface{}) (reply ncs.ListTeamServerAccountsReply, errorInfo pi.ErrorInfo) {
+// requestContext - returns the context for one attempt of a request on the subject. The call timeout, the subject timeout,
+// or the client timeout is applied, in that order, and the attempt ends at the earlier of that timeout and the context
+// deadline. A caller deadline longer than the timeout leaves time for retries.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) requestContext(ctx context.Context, subject string, options callOptions) (requestCtx context.Context, cancel context.CancelFunc) {
 
 	var (
-		tReply *nats.Msg
+		tTimeout = options.timeout
 	)
 
-	if tReply, errorInfo = listTeamServerAccounts(
-		clientPtr.styhCustomerConfig.clientId,
-		clientPtr.styhCustomerConfig.secretKey,
-		clientPtr.styhCustomerConfig.username,
-		clientPtr.natsService.InstanceName,
-		request.(ncs.ListTeamServerAccountsRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
-		return
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if tTimeout <= 0 {
+		tTimeout = clientPtr.subjectTimeouts[subject]
+	}
+	if tTimeout <= 0 {
+		tTimeout = clientPtr.requestTimeout
+	}
+	if tTimeout <= 0 {
+		return context.WithCancel(ctx)
 	}
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
+	return context.WithTimeout(ctx, tTimeout)
+}
+
+// startRequest - counts the request as in flight, so Close waits for it. False is returned once Close has been called.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) startRequest() bool {
+
+	clientPtr.closeMutex.RLock()
+	defer clientPtr.closeMutex.RUnlock()
+
+	if clientPtr.closing {
+		return false
 	}
+	clientPtr.inFlight.Add(1)
 
-	return
+	return true
 }
 
-// SynaidaListTeams - returns information about all your teams
-func (clientPtr *NCClient) SynaidaListTeams(request interface{}) (reply ncs.ListTeamsReply, errorInfo pi.ErrorInfo) {
+// waitForRequests - waits for the in-flight requests to finish. The context error is returned if it is done first.
+//
+//	Customer Messages: None
+//	Errors: ctx.Err
+//	Verifications: None
+func (clientPtr *NCClient) waitForRequests(ctx context.Context) (err error) {
 
 	var (
-		tReply *nats.Msg
+		tDone = make(chan struct{})
 	)
 
-	if tReply, errorInfo = listTeams(
-		clientPtr.styhCustomerConfig.clientId,
-		clientPtr.styhCustomerConfig.secretKey,
-		clientPtr.styhCustomerConfig.username,
-		clientPtr.natsService.InstanceName,
-		request.(ncs.ListTeamsRequest),
-		clientPtr.natsService.ConnPtr,
-	); errorInfo.Error != nil {
-		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.VAL_EMPTY)
-		return
-	}
+	go func() {
+		clientPtr.inFlight.Wait()
+		close(tDone)
+	}()
 
-	if errorInfo.Error = json.Unmarshal(tReply.Data, &reply); errorInfo.Error != nil {
-		pi.PrintErrorInfo(errorInfo)
+	select {
+	case <-tDone:
+	case <-ctx.Done():
+		err = ctx.Err()
 	}
 
 	return
@@ -0,0 +1,119 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ncs "github.com/sty-holdings/nats-connect-shared/v2024"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+func TestClose(tPtr *testing.T) {
+
+	var (
+		tCancel    context.CancelFunc
+		tClientPtr *NCClient
+		tClosed    = make(chan pi.ErrorInfo)
+		tCtx       context.Context
+		tErrorInfo pi.ErrorInfo
+		tInFlight  = make(chan pi.ErrorInfo)
+		tRelease   = make(chan struct{})
+		tStarted   = make(chan struct{})
+	)
+
+	tClientPtr = newMockClient(func(ctx context.Context, callPtr *Call, next Invoker) (errorInfo pi.ErrorInfo) {
+		close(tStarted)
+		<-tRelease
+		return
+	})
+
+	go func() {
+		_, tRequestErrorInfo := tClientPtr.SynaidaGetTeamCtx(context.Background(), ncs.GetTeamRequest{})
+		tInFlight <- tRequestErrorInfo
+	}()
+	<-tStarted
+
+	tCtx, tCancel = context.WithCancel(context.Background())
+	tCancel()
+	if tErrorInfo = tClientPtr.Close(tCtx); errors.Is(tErrorInfo.Error, context.Canceled) == false {
+		tPtr.Errorf("got error %v, want %v while a request is in flight", tErrorInfo.Error, context.Canceled)
+	}
+
+	if _, tErrorInfo = tClientPtr.SynaidaGetTeamCtx(context.Background(), ncs.GetTeamRequest{}); errors.Is(tErrorInfo.Error, ErrClientClosed) == false {
+		tPtr.Errorf("got error %v, want %v after Close", tErrorInfo.Error, ErrClientClosed)
+	}
+
+	go func() {
+		tClosed <- tClientPtr.Close(context.Background())
+	}()
+	select {
+	case tErrorInfo = <-tClosed:
+		tPtr.Fatalf("got Close returned with %v, want it to wait for the request in flight", tErrorInfo.Error)
+	default:
+	}
+
+	close(tRelease)
+	if tErrorInfo = <-tInFlight; tErrorInfo.Error != nil {
+		tPtr.Errorf("got error %v, want the request in flight to finish", tErrorInfo.Error)
+	}
+	if tErrorInfo = <-tClosed; tErrorInfo.Error != nil {
+		tPtr.Errorf("got error %v, want Close to finish after the request", tErrorInfo.Error)
+	}
+}
+
+func TestRequestContext(tPtr *testing.T) {
+
+	var (
+		tCancel    context.CancelFunc
+		tClientPtr = &NCClient{requestTimeout: time.Second, subjectTimeouts: map[string]time.Duration{"SLOW_SUBJECT": time.Hour}}
+		tCtx       context.Context
+		tDeadline  time.Time
+		tParentCtx context.Context
+	)
+
+	tParentCtx, tCancel = context.WithTimeout(context.Background(), time.Minute)
+	defer tCancel()
+
+	tCtx, tCancel = tClientPtr.requestContext(tParentCtx, "TEST_SUBJECT", callOptions{})
+	defer tCancel()
+	if tDeadline, _ = tCtx.Deadline(); time.Until(tDeadline) > time.Second {
+		tPtr.Errorf("got the attempt ending in %v, want the client timeout within the caller deadline", time.Until(tDeadline))
+	}
+
+	tCtx, tCancel = tClientPtr.requestContext(tParentCtx, "SLOW_SUBJECT", callOptions{})
+	defer tCancel()
+	if tDeadline, _ = tCtx.Deadline(); time.Until(tDeadline) <= time.Second {
+		tPtr.Errorf("got the attempt ending in %v, want the caller deadline before the subject timeout", time.Until(tDeadline))
+	}
+
+	tCtx, tCancel = tClientPtr.requestContext(tParentCtx, "SLOW_SUBJECT", callOptions{timeout: time.Millisecond})
+	defer tCancel()
+	if tDeadline, _ = tCtx.Deadline(); time.Until(tDeadline) > time.Millisecond {
+		tPtr.Errorf("got the attempt ending in %v, want the call timeout", time.Until(tDeadline))
+	}
+}
@@ -0,0 +1,183 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/nats-io/nats.go"
+
+	ctv "github.com/sty-holdings/constant-type-vars-go/v2024"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+//goland:noinspection ALL
+const (
+	COMPRESSION_GZIP Compression = "gzip"
+	COMPRESSION_S2   Compression = "s2"
+	//
+	DEFAULT_COMPRESSION_THRESHOLD = 8 * 1024
+	MAX_DECOMPRESSED_REPLY_SIZE   = 64 * 1024 * 1024
+	//
+	HEADER_ACCEPT_ENCODING  = "NC-Accept-Encoding"  // Request header. The compressions the client can read in the reply.
+	HEADER_CONTENT_ENCODING = "NC-Content-Encoding" // Request and reply header. The compression applied before encryption.
+)
+
+// Compression - the algorithm used to compress payloads before they are encrypted.
+type Compression string
+
+// CompressionSettings - controls request compression. Requests with marshalled JSON larger than Threshold are compressed
+// before they are encrypted. An empty Algorithm turns compression off.
+type CompressionSettings struct {
+	Algorithm Compression // COMPRESSION_GZIP or COMPRESSION_S2.
+	Threshold int         // Smallest payload, in bytes, that is compressed. The default is DEFAULT_COMPRESSION_THRESHOLD.
+}
+
+// compressPayload - compresses the payload when it is larger than the threshold. The algorithm used is returned, or an
+// empty string when the payload wasn't compressed. S2 uses the block format.
+//
+//	Customer Messages: None
+//	Errors: ErrUnsupportedCompression, returned from gzip.Writer
+//	Verifications: None
+func compressPayload(settings CompressionSettings, subject string, payload []byte) (compressed []byte, algorithm Compression, errorInfo pi.ErrorInfo) {
+
+	var (
+		tBuffer    bytes.Buffer
+		tWriterPtr *gzip.Writer
+	)
+
+	switch {
+	case settings.Algorithm == ctv.VAL_EMPTY:
+		return payload, ctv.VAL_EMPTY, errorInfo
+	case settings.Algorithm != COMPRESSION_GZIP && settings.Algorithm != COMPRESSION_S2:
+		errorInfo = pi.NewErrorInfo(ErrUnsupportedCompression, fmt.Sprintf("%v%v%v%v", ctv.TXT_SUBJECT, subject, TXT_COMPRESSION, settings.Algorithm))
+		return
+	case len(payload) <= getCompressionThreshold(settings):
+		return payload, ctv.VAL_EMPTY, errorInfo
+	}
+
+	switch settings.Algorithm {
+	case COMPRESSION_GZIP:
+		tWriterPtr = gzip.NewWriter(&tBuffer)
+		if _, errorInfo.Error = tWriterPtr.Write(payload); errorInfo.Error == nil {
+			errorInfo.Error = tWriterPtr.Close()
+		}
+		if errorInfo.Error != nil {
+			errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v%v%v", ctv.TXT_SUBJECT, subject, TXT_COMPRESSION, settings.Algorithm))
+			return
+		}
+		return tBuffer.Bytes(), COMPRESSION_GZIP, errorInfo
+	default:
+		return s2.Encode(nil, payload), COMPRESSION_S2, errorInfo
+	}
+}
+
+// decompressReply - decompresses the reply data using the algorithm in the NC-Content-Encoding reply header. Data is
+// returned as is when the header is missing. Replies larger than MAX_DECOMPRESSED_REPLY_SIZE after decompressing are rejected.
+// S2 uses the block format.
+//
+//	Customer Messages: None
+//	Errors: ErrReplyTooLarge, ErrUnsupportedCompression, returned from gzip.NewReader, s2.Decode
+//	Verifications: None
+func decompressReply(subject string, replyPtr *nats.Msg, data []byte) (decompressed []byte, errorInfo pi.ErrorInfo) {
+
+	var (
+		tAlgorithm   = Compression(replyPtr.Header.Get(HEADER_CONTENT_ENCODING))
+		tDecodedSize int
+		tReaderPtr   *gzip.Reader
+	)
+
+	switch tAlgorithm {
+	case ctv.VAL_EMPTY:
+		return data, errorInfo
+	case COMPRESSION_GZIP:
+		if tReaderPtr, errorInfo.Error = gzip.NewReader(bytes.NewReader(data)); errorInfo.Error != nil {
+			errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v%v%v", ctv.TXT_SUBJECT, subject, TXT_COMPRESSION, tAlgorithm))
+			return
+		}
+		defer tReaderPtr.Close()
+	case COMPRESSION_S2:
+		if tDecodedSize, errorInfo.Error = s2.DecodedLen(data); errorInfo.Error == nil && tDecodedSize > MAX_DECOMPRESSED_REPLY_SIZE {
+			errorInfo.Error = ErrReplyTooLarge
+		}
+		if errorInfo.Error == nil {
+			decompressed, errorInfo.Error = s2.Decode(nil, data)
+		}
+		if errorInfo.Error != nil {
+			errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v%v%v", ctv.TXT_SUBJECT, subject, TXT_COMPRESSION, tAlgorithm))
+		}
+		return
+	default:
+		errorInfo = pi.NewErrorInfo(ErrUnsupportedCompression, fmt.Sprintf("%v%v%v%v", ctv.TXT_SUBJECT, subject, TXT_COMPRESSION, tAlgorithm))
+		return
+	}
+
+	if decompressed, errorInfo.Error = io.ReadAll(io.LimitReader(tReaderPtr, MAX_DECOMPRESSED_REPLY_SIZE+1)); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v%v%v", ctv.TXT_SUBJECT, subject, TXT_COMPRESSION, tAlgorithm))
+		return
+	}
+	if len(decompressed) > MAX_DECOMPRESSED_REPLY_SIZE {
+		errorInfo = pi.NewErrorInfo(ErrReplyTooLarge, fmt.Sprintf("%v%v%v%v", ctv.TXT_SUBJECT, subject, TXT_COMPRESSION, tAlgorithm))
+		return nil, errorInfo
+	}
+
+	return
+}
+
+// getCompressionThreshold - returns the threshold, or DEFAULT_COMPRESSION_THRESHOLD when it isn't set.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func getCompressionThreshold(settings CompressionSettings) int {
+
+	if settings.Threshold > 0 {
+		return settings.Threshold
+	}
+
+	return DEFAULT_COMPRESSION_THRESHOLD
+}
+
+// setCompressionHeaders - tells the server which compressions the client reads when compression is on, and which
+// compression was applied to the request.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func setCompressionHeaders(settings CompressionSettings, algorithm Compression, requestMsgPtr *nats.Msg) {
+
+	if settings.Algorithm == ctv.VAL_EMPTY {
+		return
+	}
+
+	requestMsgPtr.Header.Set(HEADER_ACCEPT_ENCODING, strings.Join([]string{string(COMPRESSION_GZIP), string(COMPRESSION_S2)}, ", "))
+	if algorithm != ctv.VAL_EMPTY {
+		requestMsgPtr.Header.Set(HEADER_CONTENT_ENCODING, string(algorithm))
+	}
+}
@@ -0,0 +1,169 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"testing"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/nats-io/nats.go"
+
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+func TestCompressionRoundTrip(tPtr *testing.T) {
+
+	var (
+		tPayload = bytes.Repeat([]byte(`{"name":"value"}`), 1024)
+		tests    = []struct {
+			name          string
+			settings      CompressionSettings
+			wantAlgorithm Compression
+			wantError     error
+		}{
+			{
+				name:          "Positive Case: gzip.",
+				settings:      CompressionSettings{Algorithm: COMPRESSION_GZIP},
+				wantAlgorithm: COMPRESSION_GZIP,
+			},
+			{
+				name:          "Positive Case: s2.",
+				settings:      CompressionSettings{Algorithm: COMPRESSION_S2},
+				wantAlgorithm: COMPRESSION_S2,
+			},
+			{
+				name:     "Positive Case: A payload at the threshold isn't compressed.",
+				settings: CompressionSettings{Algorithm: COMPRESSION_GZIP, Threshold: len(tPayload)},
+			},
+			{
+				name:     "Positive Case: Compression is off.",
+				settings: CompressionSettings{},
+			},
+			{
+				name:      "Negative Case: Unsupported algorithm.",
+				settings:  CompressionSettings{Algorithm: "zstd"},
+				wantError: ErrUnsupportedCompression,
+			},
+		}
+	)
+
+	for _, ts := range tests {
+		tPtr.Run(
+			ts.name, func(t *testing.T) {
+				var (
+					tAlgorithm    Compression
+					tCompressed   []byte
+					tDecompressed []byte
+					tErrorInfo    pi.ErrorInfo
+					tReply        = nats.Msg{Header: nats.Header{}}
+				)
+
+				tCompressed, tAlgorithm, tErrorInfo = compressPayload(ts.settings, "TEST_SUBJECT", tPayload)
+				if errors.Is(tErrorInfo.Error, ts.wantError) == false {
+					t.Fatalf("%v: got error %v, want %v", ts.name, tErrorInfo.Error, ts.wantError)
+				}
+				if ts.wantError != nil {
+					return
+				}
+				if tAlgorithm != ts.wantAlgorithm || (tAlgorithm == "" && bytes.Equal(tCompressed, tPayload) == false) ||
+					(tAlgorithm != "" && len(tCompressed) >= len(tPayload)) {
+					t.Fatalf("%v: got algorithm %q and %d bytes from %d", ts.name, tAlgorithm, len(tCompressed), len(tPayload))
+				}
+
+				setCompressionHeaders(ts.settings, tAlgorithm, &tReply)
+				if tDecompressed, tErrorInfo = decompressReply("TEST_SUBJECT", &tReply, tCompressed); tErrorInfo.Error != nil {
+					t.Fatalf("%v: got error %v", ts.name, tErrorInfo.Error)
+				}
+				if bytes.Equal(tDecompressed, tPayload) == false {
+					t.Errorf("%v: the round trip changed the payload", ts.name)
+				}
+			},
+		)
+	}
+}
+
+func TestDecompressReplyLimit(tPtr *testing.T) {
+
+	var (
+		tAtLimit  = make([]byte, MAX_DECOMPRESSED_REPLY_SIZE)
+		tOverSize = make([]byte, MAX_DECOMPRESSED_REPLY_SIZE+1)
+		tests     = []struct {
+			name      string
+			algorithm Compression
+			data      []byte
+			wantError error
+		}{
+			{name: "Positive Case: gzip at the limit.", algorithm: COMPRESSION_GZIP, data: gzipData(tPtr, tAtLimit)},
+			{name: "Negative Case: gzip over the limit.", algorithm: COMPRESSION_GZIP, data: gzipData(tPtr, tOverSize), wantError: ErrReplyTooLarge},
+			{name: "Positive Case: s2 at the limit.", algorithm: COMPRESSION_S2, data: s2.Encode(nil, tAtLimit)},
+			{name: "Negative Case: s2 over the limit.", algorithm: COMPRESSION_S2, data: s2.Encode(nil, tOverSize), wantError: ErrReplyTooLarge},
+			{name: "Negative Case: Corrupt gzip.", algorithm: COMPRESSION_GZIP, data: []byte("this is not gzip data"), wantError: gzip.ErrHeader},
+			{name: "Negative Case: Unsupported algorithm.", algorithm: "zstd", data: []byte("{}"), wantError: ErrUnsupportedCompression},
+		}
+	)
+
+	for _, ts := range tests {
+		tPtr.Run(
+			ts.name, func(t *testing.T) {
+				var (
+					tDecompressed []byte
+					tErrorInfo    pi.ErrorInfo
+					tReply        = nats.Msg{Header: nats.Header{HEADER_CONTENT_ENCODING: []string{string(ts.algorithm)}}}
+				)
+
+				tDecompressed, tErrorInfo = decompressReply("TEST_SUBJECT", &tReply, ts.data)
+				if errors.Is(tErrorInfo.Error, ts.wantError) == false {
+					t.Fatalf("%v: got error %v, want %v", ts.name, tErrorInfo.Error, ts.wantError)
+				}
+				if ts.wantError == nil && len(tDecompressed) != MAX_DECOMPRESSED_REPLY_SIZE {
+					t.Errorf("%v: got %d bytes, want %d", ts.name, len(tDecompressed), MAX_DECOMPRESSED_REPLY_SIZE)
+				}
+				if ts.wantError != nil && tDecompressed != nil {
+					t.Errorf("%v: got %d bytes with the error, want none", ts.name, len(tDecompressed))
+				}
+			},
+		)
+	}
+}
+
+// gzipData - returns the data compressed with gzip.
+func gzipData(tPtr *testing.T, data []byte) []byte {
+
+	var (
+		tBuffer    bytes.Buffer
+		tWriterPtr = gzip.NewWriter(&tBuffer)
+	)
+
+	if _, tError := tWriterPtr.Write(data); tError != nil {
+		tPtr.Fatal(tError)
+	}
+	if tError := tWriterPtr.Close(); tError != nil {
+		tPtr.Fatal(tError)
+	}
+
+	return tBuffer.Bytes()
+}
@@ -0,0 +1,105 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"github.com/nats-io/nats.go"
+)
+
+// ConnectionHandlers - callbacks for the NATS Connect connection events. Callbacks that are nil are not registered.
+// The callbacks are run by the NATS client on its own goroutine, so they must not block.
+type ConnectionHandlers struct {
+	AsyncError    func(subject string, err error) // Errors that don't belong to a request, such as a slow consumer.
+	Closed        func()                          // The connection is closed and will not reconnect.
+	DisconnectErr func(err error)                 // The connection was lost. The error is nil when the client disconnected.
+	Reconnect     func(connectedURL string)       // The connection was restored.
+}
+
+// Status - returns the state of the NATS Connect connection. nats.DISCONNECTED is returned before the client connects.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) Status() nats.Status {
+
+	if clientPtr.natsService.ConnPtr == nil {
+		return nats.DISCONNECTED
+	}
+
+	return clientPtr.natsService.ConnPtr.Status()
+}
+
+// getConnectionHandlerOptions - returns the nats options that register the callbacks. They are passed to nats.Connect, so
+// events during the first connect are not missed.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func getConnectionHandlerOptions(handlers ConnectionHandlers) (opts []nats.Option) {
+
+	if handlers.AsyncError != nil {
+		opts = append(
+			opts, nats.ErrorHandler(
+				func(_ *nats.Conn, subscriptionPtr *nats.Subscription, err error) {
+					var (
+						tSubject string
+					)
+					if subscriptionPtr != nil {
+						tSubject = subscriptionPtr.Subject
+					}
+					handlers.AsyncError(tSubject, err)
+				},
+			),
+		)
+	}
+	if handlers.Closed != nil {
+		opts = append(
+			opts, nats.ClosedHandler(
+				func(_ *nats.Conn) {
+					handlers.Closed()
+				},
+			),
+		)
+	}
+	if handlers.DisconnectErr != nil {
+		opts = append(
+			opts, nats.DisconnectErrHandler(
+				func(_ *nats.Conn, err error) {
+					handlers.DisconnectErr(err)
+				},
+			),
+		)
+	}
+	if handlers.Reconnect != nil {
+		opts = append(
+			opts, nats.ReconnectHandler(
+				func(connPtr *nats.Conn) {
+					handlers.Reconnect(connPtr.ConnectedUrlRedacted())
+				},
+			),
+		)
+	}
+
+	return
+}
@@ -0,0 +1,68 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestGetConnectionHandlerOptions(tPtr *testing.T) {
+
+	var (
+		tAsyncSubject string
+		tClosed       bool
+		tDisconnected error
+		tOptions      nats.Options
+		tWantError    = errors.New("slow consumer")
+	)
+
+	for _, opt := range getConnectionHandlerOptions(
+		ConnectionHandlers{
+			AsyncError:    func(subject string, err error) { tAsyncSubject = subject },
+			Closed:        func() { tClosed = true },
+			DisconnectErr: func(err error) { tDisconnected = err },
+		},
+	) {
+		if tError := opt(&tOptions); tError != nil {
+			tPtr.Fatal(tError)
+		}
+	}
+
+	if tOptions.AsyncErrorCB == nil || tOptions.ClosedCB == nil || tOptions.DisconnectedErrCB == nil {
+		tPtr.Fatalf("got a nil callback, want the handlers set as connect options")
+	}
+	if tOptions.ReconnectedCB != nil {
+		tPtr.Errorf("got a reconnect callback, want none for a nil handler")
+	}
+
+	tOptions.AsyncErrorCB(nil, &nats.Subscription{Subject: "TEST_SUBJECT"}, tWantError)
+	tOptions.ClosedCB(nil)
+	tOptions.DisconnectedErrCB(nil, tWantError)
+	if tAsyncSubject != "TEST_SUBJECT" || tClosed == false || tDisconnected != tWantError {
+		tPtr.Errorf("got subject %q, closed %v, and disconnect error %v, want the handlers called", tAsyncSubject, tClosed, tDisconnected)
+	}
+}
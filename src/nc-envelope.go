@@ -0,0 +1,231 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"golang.org/x/crypto/hkdf"
+
+	ctv "github.com/sty-holdings/constant-type-vars-go/v2024"
+	jwts "github.com/sty-holdings/sty-shared/v2024/jwtServices"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+//goland:noinspection ALL
+const (
+	HEADER_REPLY_ENCRYPTED   = "NC-Reply-Encrypted" // Reply header. True when the reply data was encrypted with the client's secret key.
+	HEADER_REPLY_ENVELOPE    = "NC-Reply-Envelope"  // Request header. Lists the reply protections the client requires.
+	HEADER_REPLY_SIGNATURE   = "NC-Reply-Signature" // Reply header. Base64 HMAC-SHA256 of the request, signedReplyHeaders and the reply data.
+	REPLY_ENVELOPE_ENCRYPTED = "encrypted"
+	REPLY_ENVELOPE_SIGNED    = "signed"
+	REPLY_SIGNATURE_KEY_INFO = "nats-connect reply signature" // HKDF info for the signature key, so it isn't the encryption key.
+	REPLY_SIGNATURE_KEY_SIZE = 32
+)
+
+// signedReplyHeaders - the reply headers that control how the reply is decoded or paged. They are signed with the reply
+// data, in this order, so they can't be added, removed, or changed without failing the signature check.
+var signedReplyHeaders = []string{micro.ErrorHeader, micro.ErrorCodeHeader, HEADER_REPLY_ENCRYPTED, HEADER_CONTENT_ENCODING, HEADER_NEXT_PAGE_TOKEN}
+
+// ReplyEnvelopeSettings - sets which reply protections are required. Encrypted replies are always decrypted and signed
+// replies are always verified, these settings reject replies that don't have them. Both are off by default, so a reply
+// without the NC-Reply-Signature header is accepted unverified. Set RequireSignature to reject unsigned or spoofed replies.
+type ReplyEnvelopeSettings struct {
+	RequireEncryption bool // Replies that aren't encrypted are rejected with ErrReplyNotEncrypted.
+	RequireSignature  bool // Replies that aren't signed are rejected with a ReplySignatureError. Off by default.
+}
+
+// ReplySignatureError - is returned when a reply signature is missing or doesn't match the reply data. The reply is not
+// decrypted or unmarshalled. It matches ErrReplySignatureInvalid using errors.Is.
+type ReplySignatureError struct {
+	Reason  string
+	Subject string
+}
+
+// Error - returns the error message with the subject and why the signature was rejected.
+func (errorPtr *ReplySignatureError) Error() string {
+
+	return fmt.Sprintf("%v %v%v Reason: %v", REPLY_SIGNATURE_INVALID, ctv.TXT_SUBJECT, errorPtr.Subject, errorPtr.Reason)
+}
+
+// Unwrap - allows errors.Is to match ErrReplySignatureInvalid.
+func (errorPtr *ReplySignatureError) Unwrap() error {
+
+	return ErrReplySignatureInvalid
+}
+
+// openReply - verifies the reply signature, then decrypts and decompresses the reply data. The signature is checked against
+// the request and the headers and data as they were received, so a tampered reply, or a reply to another request, is rejected
+// before it is decrypted or unmarshalled. Unsigned replies are only rejected when RequireSignature is set. Errors signalled by
+// the server in the headers are returned as a ServerError.
+//
+//	Customer Messages: None
+//	Errors: ReplySignatureError, ServerError, ErrReplyNotEncrypted, returned from jwts.DecryptToByte, decompressReply
+//	Verifications: None
+func openReply(
+	clientId, secretKey, subject, requestId string,
+	settings ReplyEnvelopeSettings,
+	replyPtr *nats.Msg,
+) (
+	data []byte,
+	errorInfo pi.ErrorInfo,
+) {
+
+	var (
+		tEncrypted      bool
+		tServerErrorPtr *ServerError
+		tSignature      = replyPtr.Header.Get(HEADER_REPLY_SIGNATURE)
+	)
+
+	switch {
+	case tSignature != ctv.VAL_EMPTY:
+		if errorInfo = verifyReplySignature(secretKey, subject, requestId, tSignature, replyPtr); errorInfo.Error != nil {
+			return
+		}
+	case settings.RequireSignature:
+		errorInfo = pi.NewErrorInfo(&ReplySignatureError{Reason: REPLY_SIGNATURE_MISSING, Subject: subject}, fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, subject))
+		return
+	}
+
+	if tServerErrorPtr = getServerError(subject, replyPtr); tServerErrorPtr != nil {
+		errorInfo = newServerErrorInfo(tServerErrorPtr)
+		return
+	}
+
+	tEncrypted, _ = strconv.ParseBool(replyPtr.Header.Get(HEADER_REPLY_ENCRYPTED))
+	switch {
+	case tEncrypted:
+		if data, errorInfo = jwts.DecryptToByte(clientId, secretKey, string(replyPtr.Data)); errorInfo.Error != nil {
+			errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v - %v%v", errorInfo.AdditionalInfo, ctv.TXT_SUBJECT, subject))
+		}
+	case settings.RequireEncryption:
+		errorInfo = pi.NewErrorInfo(ErrReplyNotEncrypted, fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, subject))
+	default:
+		data = replyPtr.Data
+	}
+	if errorInfo.Error != nil {
+		return
+	}
+
+	data, errorInfo = decompressReply(subject, replyPtr, data)
+
+	return
+}
+
+// setReplyEnvelopeHeader - tells the server which reply protections the client requires.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func setReplyEnvelopeHeader(settings ReplyEnvelopeSettings, requestMsgPtr *nats.Msg) {
+
+	var (
+		tProtections []string
+	)
+
+	if settings.RequireEncryption {
+		tProtections = append(tProtections, REPLY_ENVELOPE_ENCRYPTED)
+	}
+	if settings.RequireSignature {
+		tProtections = append(tProtections, REPLY_ENVELOPE_SIGNED)
+	}
+	if len(tProtections) == ctv.VAL_ZERO {
+		return
+	}
+
+	if requestMsgPtr.Header == nil {
+		requestMsgPtr.Header = nats.Header{}
+	}
+	requestMsgPtr.Header.Set(HEADER_REPLY_ENVELOPE, strings.Join(tProtections, ","))
+}
+
+// getReplySignatureKey - derives the reply signature key from the decoded secret key using HKDF-SHA256, so the key that
+// encrypts requests isn't also used to sign replies.
+//
+//	Customer Messages: None
+//	Errors: returned from base64.StdEncoding.DecodeString, io.ReadFull
+//	Verifications: None
+func getReplySignatureKey(secretKey string) (signatureKey []byte, err error) {
+
+	var (
+		tDecodedKey []byte
+	)
+
+	if tDecodedKey, err = base64.StdEncoding.DecodeString(secretKey); err != nil {
+		return
+	}
+
+	signatureKey = make([]byte, REPLY_SIGNATURE_KEY_SIZE)
+	_, err = io.ReadFull(hkdf.New(sha256.New, tDecodedKey, nil, []byte(REPLY_SIGNATURE_KEY_INFO)), signatureKey)
+
+	return
+}
+
+// verifyReplySignature - checks the base64 HMAC-SHA256 signature of the reply, keyed with getReplySignatureKey. The signed
+// material is a line with the subject, a line with the request id, a "name:value\r\n" line for each of signedReplyHeaders,
+// with an empty value when the header isn't set, then "\r\n" and the reply data. Lines end with "\r\n". The comparison takes
+// the same time whether or not the signature matches.
+//
+//	Customer Messages: None
+//	Errors: ReplySignatureError, returned from getReplySignatureKey
+//	Verifications: None
+func verifyReplySignature(secretKey, subject, requestId, signature string, replyPtr *nats.Msg) (errorInfo pi.ErrorInfo) {
+
+	var (
+		tDecodedSignature []byte
+		tHash             hash.Hash
+		tSignatureKey     []byte
+	)
+
+	if tSignatureKey, errorInfo.Error = getReplySignatureKey(secretKey); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, subject))
+		return
+	}
+	if tDecodedSignature, errorInfo.Error = base64.StdEncoding.DecodeString(signature); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(&ReplySignatureError{Reason: REPLY_SIGNATURE_MALFORMED, Subject: subject}, fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, subject))
+		return
+	}
+
+	tHash = hmac.New(sha256.New, tSignatureKey)
+	fmt.Fprintf(tHash, "%v\r\n%v\r\n", subject, requestId)
+	for _, name := range signedReplyHeaders {
+		fmt.Fprintf(tHash, "%v:%v\r\n", name, replyPtr.Header.Get(name))
+	}
+	tHash.Write([]byte("\r\n"))
+	tHash.Write(replyPtr.Data)
+	if hmac.Equal(tHash.Sum(nil), tDecodedSignature) == false {
+		errorInfo = pi.NewErrorInfo(&ReplySignatureError{Reason: REPLY_SIGNATURE_MISMATCH, Subject: subject}, fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, subject))
+	}
+
+	return
+}
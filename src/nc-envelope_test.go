@@ -0,0 +1,209 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"golang.org/x/crypto/hkdf"
+
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+func TestVerifyReplySignature(tPtr *testing.T) {
+
+	var (
+		tSecretKey = base64.StdEncoding.EncodeToString(make([]byte, 32))
+		tSigned    = nats.Header{HEADER_CONTENT_ENCODING: []string{string(COMPRESSION_GZIP)}, HEADER_NEXT_PAGE_TOKEN: []string{"2"}}
+		tests      = []struct {
+			name       string
+			header     nats.Header // The header received. The signature always covers tSigned.
+			data       string
+			requestId  string // Replaces REQUEST_ID when set.
+			subject    string // Replaces TEST_SUBJECT when set.
+			signature  string // Replaces the signature when set.
+			wantReason string
+		}{
+			{
+				name:   "Positive Case: Signed request, headers and data.",
+				header: tSigned,
+				data:   `{"response":{}}`,
+			},
+			{
+				name:   "Positive Case: Headers that aren't signed can change.",
+				header: nats.Header{HEADER_CONTENT_ENCODING: []string{string(COMPRESSION_GZIP)}, HEADER_NEXT_PAGE_TOKEN: []string{"2"}, "Other": []string{"1"}},
+				data:   `{"response":{}}`,
+			},
+			{
+				name:       "Negative Case: The data was changed.",
+				header:     tSigned,
+				data:       `{"response":{"changed":true}}`,
+				wantReason: REPLY_SIGNATURE_MISMATCH,
+			},
+			{
+				name:       "Negative Case: The reply answers another request.",
+				header:     tSigned,
+				data:       `{"response":{}}`,
+				requestId:  "OTHER_REQUEST_ID",
+				wantReason: REPLY_SIGNATURE_MISMATCH,
+			},
+			{
+				name:       "Negative Case: The reply answers another subject.",
+				header:     tSigned,
+				data:       `{"response":{}}`,
+				subject:    "OTHER_SUBJECT",
+				wantReason: REPLY_SIGNATURE_MISMATCH,
+			},
+			{
+				name:       "Negative Case: The next page token was changed.",
+				header:     nats.Header{HEADER_CONTENT_ENCODING: []string{string(COMPRESSION_GZIP)}, HEADER_NEXT_PAGE_TOKEN: []string{"9"}},
+				data:       `{"response":{}}`,
+				wantReason: REPLY_SIGNATURE_MISMATCH,
+			},
+			{
+				name:       "Negative Case: The encrypted header was added.",
+				header:     nats.Header{HEADER_CONTENT_ENCODING: []string{string(COMPRESSION_GZIP)}, HEADER_NEXT_PAGE_TOKEN: []string{"2"}, HEADER_REPLY_ENCRYPTED: []string{"true"}},
+				data:       `{"response":{}}`,
+				wantReason: REPLY_SIGNATURE_MISMATCH,
+			},
+			{
+				name:       "Negative Case: The content encoding header was removed.",
+				header:     nats.Header{HEADER_NEXT_PAGE_TOKEN: []string{"2"}},
+				data:       `{"response":{}}`,
+				wantReason: REPLY_SIGNATURE_MISMATCH,
+			},
+			{
+				name:       "Negative Case: An error header was added.",
+				header:     nats.Header{HEADER_CONTENT_ENCODING: []string{string(COMPRESSION_GZIP)}, HEADER_NEXT_PAGE_TOKEN: []string{"2"}, micro.ErrorCodeHeader: []string{"500"}},
+				data:       `{"response":{}}`,
+				wantReason: REPLY_SIGNATURE_MISMATCH,
+			},
+			{
+				name:       "Negative Case: Signed with the secret key instead of the derived key.",
+				header:     tSigned,
+				data:       `{"response":{}}`,
+				signature:  signReplyWithKey(make([]byte, 32), "TEST_SUBJECT", "REQUEST_ID", tSigned, `{"response":{}}`),
+				wantReason: REPLY_SIGNATURE_MISMATCH,
+			},
+			{
+				name:       "Negative Case: The signature isn't base64.",
+				header:     tSigned,
+				data:       `{"response":{}}`,
+				signature:  "not base64!",
+				wantReason: REPLY_SIGNATURE_MALFORMED,
+			},
+		}
+	)
+
+	for _, ts := range tests {
+		tPtr.Run(
+			ts.name, func(t *testing.T) {
+				var (
+					tErrorInfo   pi.ErrorInfo
+					tReply       = nats.Msg{Data: []byte(ts.data), Header: ts.header}
+					tRequestId   = "REQUEST_ID"
+					tSignature   = signReply(tSecretKey, "TEST_SUBJECT", "REQUEST_ID", tSigned, `{"response":{}}`)
+					tSigErrorPtr *ReplySignatureError
+					tSubject     = "TEST_SUBJECT"
+				)
+
+				if ts.requestId != "" {
+					tRequestId = ts.requestId
+				}
+				if ts.subject != "" {
+					tSubject = ts.subject
+				}
+				if ts.signature != "" {
+					tSignature = ts.signature
+				}
+				tErrorInfo = verifyReplySignature(tSecretKey, tSubject, tRequestId, tSignature, &tReply)
+				if ts.wantReason == "" {
+					if tErrorInfo.Error != nil {
+						t.Errorf("%v: got error %v", ts.name, tErrorInfo.Error)
+					}
+					return
+				}
+				if errors.Is(tErrorInfo.Error, ErrReplySignatureInvalid) == false || errors.As(tErrorInfo.Error, &tSigErrorPtr) == false {
+					t.Fatalf("%v: got error %v, want a ReplySignatureError", ts.name, tErrorInfo.Error)
+				}
+				if tSigErrorPtr.Reason != ts.wantReason {
+					t.Errorf("%v: got reason %v, want %v", ts.name, tSigErrorPtr.Reason, ts.wantReason)
+				}
+			},
+		)
+	}
+}
+
+func TestOpenReplyUnsigned(tPtr *testing.T) {
+
+	var (
+		tErrorInfo pi.ErrorInfo
+		tReply     = nats.Msg{Data: []byte(`{"response":{}}`), Header: nats.Header{}}
+		tSecretKey = base64.StdEncoding.EncodeToString(make([]byte, 32))
+	)
+
+	if _, tErrorInfo = openReply("CLIENT_ID", tSecretKey, "TEST_SUBJECT", "REQUEST_ID", ReplyEnvelopeSettings{}, &tReply); tErrorInfo.Error != nil {
+		tPtr.Errorf("got error %v, want an unsigned reply accepted by default", tErrorInfo.Error)
+	}
+	_, tErrorInfo = openReply("CLIENT_ID", tSecretKey, "TEST_SUBJECT", "REQUEST_ID", ReplyEnvelopeSettings{RequireSignature: true}, &tReply)
+	if errors.Is(tErrorInfo.Error, ErrReplySignatureInvalid) == false {
+		tPtr.Errorf("got error %v, want ErrReplySignatureInvalid for an unsigned reply", tErrorInfo.Error)
+	}
+}
+
+// signReply - returns the signature the server sends for the request, header and data.
+func signReply(secretKey, subject, requestId string, header nats.Header, data string) string {
+
+	var (
+		tKey, _       = base64.StdEncoding.DecodeString(secretKey)
+		tSignatureKey = make([]byte, 32)
+	)
+
+	_, _ = io.ReadFull(hkdf.New(sha256.New, tKey, nil, []byte("nats-connect reply signature")), tSignatureKey)
+
+	return signReplyWithKey(tSignatureKey, subject, requestId, header, data)
+}
+
+// signReplyWithKey - returns the HMAC-SHA256 signature of the request, header and data using the key.
+func signReplyWithKey(key []byte, subject, requestId string, header nats.Header, data string) string {
+
+	var (
+		tHash = hmac.New(sha256.New, key)
+	)
+
+	tHash.Write([]byte(subject + "\r\n" + requestId + "\r\n"))
+	for _, name := range signedReplyHeaders {
+		tHash.Write([]byte(name + ":" + header.Get(name) + "\r\n"))
+	}
+	tHash.Write([]byte("\r\n" + data))
+
+	return base64.StdEncoding.EncodeToString(tHash.Sum(nil))
+}
@@ -0,0 +1,150 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+
+	ctv "github.com/sty-holdings/constant-type-vars-go/v2024"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+// ServerError - is returned when NATS Connect replies with an error instead of the reply. The server signals the error with
+// the Nats-Service-Error headers. The details are read from the error envelope in the reply data when there is one:
+// {"error": {"code": "", "http_status": 0, "message": ""}}, otherwise from the headers. It matches ErrNotFound,
+// ErrUnauthorized, ErrRateLimited, ErrUpstream, or ErrServerError using errors.Is, based on the HTTP status. Use errors.As to
+// read the HTTP status returned by the upstream service.
+type ServerError struct {
+	Code       string `json:"code"`
+	HTTPStatus int    `json:"http_status"`
+	Message    string `json:"message"`
+	Subject    string `json:"-"`
+}
+
+type errorEnvelope struct {
+	Error *ServerError `json:"error"`
+}
+
+// Error - returns the error message with the subject, code, and HTTP status.
+func (errorPtr *ServerError) Error() string {
+
+	return fmt.Sprintf(
+		"%v %v%v Code: %v HTTP Status: %v Message: %v",
+		SERVER_RETURNED_ERROR,
+		ctv.TXT_SUBJECT,
+		errorPtr.Subject,
+		errorPtr.Code,
+		errorPtr.HTTPStatus,
+		errorPtr.Message,
+	)
+}
+
+// Unwrap - allows errors.Is to match the sentinel error for the HTTP status. Server error statuses match ErrUpstream. Other
+// statuses, and codes that aren't an HTTP status, match ErrServerError.
+func (errorPtr *ServerError) Unwrap() error {
+
+	switch {
+	case errorPtr.HTTPStatus == http.StatusNotFound:
+		return ErrNotFound
+	case errorPtr.HTTPStatus == http.StatusUnauthorized, errorPtr.HTTPStatus == http.StatusForbidden:
+		return ErrUnauthorized
+	case errorPtr.HTTPStatus == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case errorPtr.HTTPStatus >= http.StatusInternalServerError:
+		return ErrUpstream
+	default:
+		return ErrServerError
+	}
+}
+
+// getServerError - returns the error signalled by the Nats-Service-Error headers. The error envelope in the reply data is only
+// read when the headers signal an error, and the header values fill in what the envelope is missing. Replies without the
+// headers return nil. A numeric error code is used as the HTTP status.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func getServerError(subject string, replyPtr *nats.Msg) (serverErrorPtr *ServerError) {
+
+	var (
+		tCode     = replyPtr.Header.Get(micro.ErrorCodeHeader)
+		tEnvelope errorEnvelope
+		tMessage  = replyPtr.Header.Get(micro.ErrorHeader)
+	)
+
+	if tCode == ctv.VAL_EMPTY && tMessage == ctv.VAL_EMPTY {
+		return
+	}
+
+	serverErrorPtr = &ServerError{}
+	if json.Unmarshal(replyPtr.Data, &tEnvelope) == nil && tEnvelope.Error != nil {
+		serverErrorPtr = tEnvelope.Error
+	}
+	if serverErrorPtr.Code == ctv.VAL_EMPTY {
+		serverErrorPtr.Code = tCode
+	}
+	if serverErrorPtr.Message == ctv.VAL_EMPTY {
+		serverErrorPtr.Message = tMessage
+	}
+	if serverErrorPtr.HTTPStatus == ctv.VAL_ZERO {
+		serverErrorPtr.HTTPStatus = getHTTPStatus(serverErrorPtr.Code)
+	}
+	serverErrorPtr.Subject = subject
+
+	return
+}
+
+// getHTTPStatus - returns the code as an HTTP status, or zero when the code is not an HTTP status.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func getHTTPStatus(code string) (httpStatus int) {
+
+	var (
+		tErr error
+	)
+
+	if httpStatus, tErr = strconv.Atoi(code); tErr != nil || http.StatusText(httpStatus) == ctv.VAL_EMPTY {
+		return ctv.VAL_ZERO
+	}
+
+	return
+}
+
+// newServerErrorInfo - wraps the server error in an ErrorInfo.
+//
+//	Customer Messages: None
+//	Errors: ServerError
+//	Verifications: None
+func newServerErrorInfo(serverErrorPtr *ServerError) (errorInfo pi.ErrorInfo) {
+
+	return pi.NewErrorInfo(serverErrorPtr, fmt.Sprintf("%v%v%v%v", ctv.TXT_SUBJECT, serverErrorPtr.Subject, TXT_HTTP_STATUS, serverErrorPtr.HTTPStatus))
+}
@@ -0,0 +1,126 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+func TestGetServerError(tPtr *testing.T) {
+
+	var (
+		tests = []struct {
+			name        string
+			code        string
+			message     string
+			data        string
+			wantError   error // Nil means no server error.
+			wantStatus  int
+			wantMessage string
+		}{
+			{
+				name: "Positive Case: An error envelope in the data without the headers is a reply.",
+				data: `{"error":{"code":"404","http_status":404,"message":"team not found"}}`,
+			},
+			{
+				name:        "Negative Case: Numeric code header.",
+				code:        "404",
+				message:     "team not found",
+				wantError:   ErrNotFound,
+				wantStatus:  404,
+				wantMessage: "team not found",
+			},
+			{
+				name:        "Negative Case: The envelope adds the HTTP status to the headers.",
+				code:        "rate_limited",
+				message:     "slow down",
+				data:        `{"error":{"code":"rate_limited","http_status":429}}`,
+				wantError:   ErrRateLimited,
+				wantStatus:  429,
+				wantMessage: "slow down",
+			},
+			{
+				name:        "Negative Case: Upstream server error.",
+				code:        "503",
+				message:     "unavailable",
+				wantError:   ErrUpstream,
+				wantStatus:  503,
+				wantMessage: "unavailable",
+			},
+			{
+				name:        "Negative Case: A code that isn't an HTTP status.",
+				code:        "team_missing",
+				message:     "no team",
+				wantError:   ErrServerError,
+				wantMessage: "no team",
+			},
+			{
+				name:        "Negative Case: A client error status that isn't mapped.",
+				code:        "400",
+				message:     "bad request",
+				wantError:   ErrServerError,
+				wantStatus:  400,
+				wantMessage: "bad request",
+			},
+		}
+	)
+
+	for _, ts := range tests {
+		tPtr.Run(
+			ts.name, func(t *testing.T) {
+				var (
+					tReply          = nats.Msg{Data: []byte(ts.data), Header: nats.Header{}}
+					tServerErrorPtr *ServerError
+				)
+
+				if ts.code != "" {
+					tReply.Header.Set(micro.ErrorCodeHeader, ts.code)
+				}
+				if ts.message != "" {
+					tReply.Header.Set(micro.ErrorHeader, ts.message)
+				}
+
+				if tServerErrorPtr = getServerError("TEST_SUBJECT", &tReply); ts.wantError == nil {
+					if tServerErrorPtr != nil {
+						t.Errorf("%v: got %v, want no server error", ts.name, tServerErrorPtr)
+					}
+					return
+				}
+				if tServerErrorPtr == nil {
+					t.Fatalf("%v: got no server error, want %v", ts.name, ts.wantError)
+				}
+				if errors.Is(tServerErrorPtr, ts.wantError) == false || (ts.wantError != ErrUpstream && errors.Is(tServerErrorPtr, ErrUpstream)) {
+					t.Errorf("%v: got %v, want %v", ts.name, tServerErrorPtr.Unwrap(), ts.wantError)
+				}
+				if tServerErrorPtr.HTTPStatus != ts.wantStatus || tServerErrorPtr.Message != ts.wantMessage {
+					t.Errorf("%v: got status %d and message %q, want %d and %q", ts.name, tServerErrorPtr.HTTPStatus, tServerErrorPtr.Message, ts.wantStatus, ts.wantMessage)
+				}
+			},
+		)
+	}
+}
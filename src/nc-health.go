@@ -0,0 +1,168 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	ctv "github.com/sty-holdings/constant-type-vars-go/v2024"
+	ncs "github.com/sty-holdings/nats-connect-shared/v2024"
+	jwts "github.com/sty-holdings/sty-shared/v2024/jwtServices"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+//goland:noinspection ALL
+const (
+	PEM_TYPE_CERTIFICATE = "CERTIFICATE"
+)
+
+// Health - reports whether the client is usable. It is safe to serialize for readiness probes.
+type Health struct {
+	CircuitBreakers      map[string]CircuitState `json:"circuit_breakers"`
+	ConnectedURL         string                  `json:"connected_url"`
+	Errors               []string                `json:"errors,omitempty"`
+	Healthy              bool                    `json:"healthy"`
+	RTT                  time.Duration           `json:"rtt_ns"`
+	Status               string                  `json:"status"`
+	TLSCertificateExpiry time.Time               `json:"tls_certificate_expiry"`
+	TokenExpiry          time.Time               `json:"token_expiry"`
+	TokenRefreshError    string                  `json:"token_refresh_error,omitempty"`
+	VersionCheck         HealthVersionCheck      `json:"version_check"`
+}
+
+// HealthVersionCheck - the result of the SynaidaGetVersion round trip.
+type HealthVersionCheck struct {
+	Error   string        `json:"error,omitempty"`
+	Latency time.Duration `json:"latency_ns"`
+	OK      bool          `json:"ok"`
+}
+
+// Health - checks the connection, the NATS round trip time, the Cognito token and TLS certificate expiry, and makes a
+// SynaidaGetVersion round trip using versionRequest. The version request isn't retried or served from the reply cache.
+// Healthy is true when the client is connected, nothing has expired, and the version round trip succeeded. Each failed
+// check is added to Errors.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) Health(ctx context.Context, versionRequest ncs.GetVersionRequest) (health Health) {
+
+	var (
+		tErrorInfo pi.ErrorInfo
+		tNow       = time.Now()
+		tStart     time.Time
+		tStatus    = clientPtr.Status()
+	)
+
+	health.CircuitBreakers = clientPtr.CircuitBreakerStates()
+	health.Status = tStatus.String()
+	if tStatus != nats.CONNECTED {
+		health.Errors = append(health.Errors, fmt.Sprintf("%v%v", TXT_CONNECTION_STATUS, health.Status))
+	} else {
+		health.ConnectedURL = clientPtr.natsService.ConnPtr.ConnectedUrlRedacted()
+		if health.RTT, tErrorInfo.Error = clientPtr.natsService.ConnPtr.RTT(); tErrorInfo.Error != nil {
+			health.Errors = append(health.Errors, tErrorInfo.Error.Error())
+		}
+	}
+
+	if health.TokenExpiry = clientPtr.TokenExpiry(); health.TokenExpiry.Before(tNow) {
+		health.Errors = append(health.Errors, fmt.Sprintf("%v%v", TXT_TOKEN_EXPIRED, health.TokenExpiry))
+	}
+	if clientPtr.tokenRefresherPtr != nil {
+		if tErrorInfo = clientPtr.tokenRefresherPtr.getLastErrorInfo(); tErrorInfo.Error != nil {
+			health.TokenRefreshError = tErrorInfo.Error.Error()
+		}
+	}
+
+	if health.TLSCertificateExpiry, tErrorInfo = getCertificateExpiry(clientPtr.getTLSInfo().TLSCert); tErrorInfo.Error != nil {
+		health.Errors = append(health.Errors, tErrorInfo.Error.Error())
+	} else if health.TLSCertificateExpiry.Before(tNow) {
+		health.Errors = append(health.Errors, fmt.Sprintf("%v%v", TXT_TLS_CERTIFICATE_EXPIRED, health.TLSCertificateExpiry))
+	}
+
+	tStart = time.Now()
+	if _, tErrorInfo = clientPtr.SynaidaGetVersionCtx(ctx, versionRequest, WithCallCacheBypass(), WithCallRetryPolicy(NoRetryPolicy())); tErrorInfo.Error != nil {
+		health.VersionCheck.Error = tErrorInfo.Error.Error()
+		health.Errors = append(health.Errors, health.VersionCheck.Error)
+	} else {
+		health.VersionCheck.OK = true
+	}
+	health.VersionCheck.Latency = time.Since(tStart)
+
+	health.Healthy = len(health.Errors) == ctv.VAL_ZERO
+
+	return
+}
+
+// getTLSInfo - returns the current TLS information from the refresher, which holds the latest values after a token refresh.
+// It is empty if the client isn't logged in.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) getTLSInfo() (tlsInfo jwts.TLSInfo) {
+
+	if clientPtr.tokenRefresherPtr == nil {
+		return
+	}
+
+	return clientPtr.tokenRefresherPtr.getNATSConfig().NATSTLSInfo
+}
+
+// getCertificateExpiry - returns when the first certificate in the PEM encoded value expires.
+//
+//	Customer Messages: None
+//	Errors: ErrRequiredArgumentMissing, returned from x509.ParseCertificate
+//	Verifications: None
+func getCertificateExpiry(certificatePEM string) (expiresAt time.Time, errorInfo pi.ErrorInfo) {
+
+	var (
+		tBlockPtr       *pem.Block
+		tCertificatePtr *x509.Certificate
+		tRest           = []byte(certificatePEM)
+	)
+
+	for {
+		if tBlockPtr, tRest = pem.Decode(tRest); tBlockPtr == nil {
+			errorInfo = pi.NewErrorInfo(pi.ErrRequiredArgumentMissing, fmt.Sprintf("%v%v", ctv.TXT_MISSING_PARAMETER, ctv.FN_TLS_CERTIFICATE))
+			return
+		}
+		if tBlockPtr.Type == PEM_TYPE_CERTIFICATE {
+			break
+		}
+	}
+
+	if tCertificatePtr, errorInfo.Error = x509.ParseCertificate(tBlockPtr.Bytes); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, ctv.FN_TLS_CERTIFICATE)
+		return
+	}
+
+	return tCertificatePtr.NotAfter, pi.ErrorInfo{}
+}
@@ -0,0 +1,216 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	ctv "github.com/sty-holdings/constant-type-vars-go/v2024"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+// Call - a request passing through the interceptor chain. Interceptors can read and change the fields. RequestMsgPtr is
+// set before the interceptors added with WithInterceptors are called, and the reply fields are set when next returns.
+type Call struct {
+	Attempts      int         // Attempts sent so far, including the current one.
+	NextPageToken string      // Token for the next page of a List* reply. Empty on the last page.
+	ReadOnly      bool        // True when the request doesn't change data and is safe to repeat.
+	ReplyData     []byte      // Reply body after the signature is verified and the reply is decrypted.
+	ReplyMsgPtr   *nats.Msg   // Reply as received from NATS.
+	ReplyPtr      interface{} // Pointer to the typed reply. It is decoded from ReplyData.
+	Request       interface{} // Typed request, such as ncs.ListTeamsRequest.
+	RequestId     string      // Id sent in the NC-Request-Id header.
+	RequestMsgPtr *nats.Msg   // Outgoing message after the request is marshalled and encrypted.
+	Subject       string      // Subject of the request endpoint.
+	options       callOptions
+}
+
+// Invoker - sends the call, or passes it to the next interceptor in the chain.
+type Invoker func(ctx context.Context, callPtr *Call) (errorInfo pi.ErrorInfo)
+
+// Interceptor - runs around a call. Call next to continue the chain, or return without calling it to short-circuit the
+// request, for example, to return a mocked reply by filling in callPtr.ReplyPtr.
+type Interceptor func(ctx context.Context, callPtr *Call, next Invoker) (errorInfo pi.ErrorInfo)
+
+// ChainInterceptors - combines the interceptors into one. The first interceptor is the outermost and is called first. Nil
+// interceptors are skipped.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func ChainInterceptors(interceptors ...Interceptor) Interceptor {
+
+	return func(ctx context.Context, callPtr *Call, next Invoker) pi.ErrorInfo {
+		return chainInvoker(interceptors, next)(ctx, callPtr)
+	}
+}
+
+// chainInvoker - wraps the invoker with the interceptors, so the first interceptor is called first.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func chainInvoker(interceptors []Interceptor, invoker Invoker) Invoker {
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if interceptors[i] == nil {
+			continue
+		}
+		tInterceptor, tNext := interceptors[i], invoker
+		invoker = func(ctx context.Context, callPtr *Call) pi.ErrorInfo {
+			return tInterceptor(ctx, callPtr, tNext)
+		}
+	}
+
+	return invoker
+}
+
+// attemptRequest - applies the attempt timeout and the subject's circuit breaker, then counts the attempt.
+//
+//	Customer Messages: None
+//	Errors: CircuitOpenError, returned from next
+//	Verifications: None
+func (clientPtr *NCClient) attemptRequest(ctx context.Context, callPtr *Call, next Invoker) (errorInfo pi.ErrorInfo) {
+
+	var (
+		tCancel     context.CancelFunc
+		tGeneration uint64
+	)
+
+	if tGeneration, errorInfo = clientPtr.circuitBreakersPtr.allow(callPtr.Subject); errorInfo.Error != nil {
+		return
+	}
+
+	ctx, tCancel = clientPtr.requestContext(ctx, callPtr.Subject, callPtr.options)
+	defer tCancel()
+
+	callPtr.Attempts++
+	errorInfo = next(ctx, callPtr)
+	clientPtr.circuitBreakersPtr.record(callPtr.Subject, tGeneration, errorInfo.Error)
+
+	return
+}
+
+// encodeRequest - marshals and encrypts the request into RequestMsgPtr. The reply envelope, paging, and trace context headers
+// are added. It runs for each attempt, so retries are marshalled and encrypted again.
+//
+//	Customer Messages: None
+//	Errors: returned from buildRequestMsg, next
+//	Verifications: None
+func (clientPtr *NCClient) encodeRequest(ctx context.Context, callPtr *Call, next Invoker) (errorInfo pi.ErrorInfo) {
+
+	if callPtr.RequestMsgPtr, errorInfo = buildRequestMsg(
+		clientPtr.styhCustomerConfig.clientId,
+		clientPtr.styhCustomerConfig.secretKey,
+		clientPtr.styhCustomerConfig.username,
+		callPtr.Subject,
+		callPtr.RequestId,
+		clientPtr.compression,
+		callPtr.Request,
+	); errorInfo.Error != nil {
+		return
+	}
+	setReplyEnvelopeHeader(clientPtr.replyEnvelope, callPtr.RequestMsgPtr)
+	setPageHeaders(callPtr.options, callPtr.RequestMsgPtr)
+	injectTraceContext(ctx, callPtr.RequestMsgPtr)
+
+	return next(ctx, callPtr)
+}
+
+// newInvoker - returns the chain used for each request. Logging sees the whole request, including cached replies. Metrics
+// see requests that weren't answered from the cache, retry repeats the rest of the chain, each attempt waits for the rate
+// limiter, and the interceptors added with WithInterceptors see each attempt after it is encrypted.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) newInvoker() Invoker {
+
+	var (
+		tInterceptors = []Interceptor{
+			LoggingInterceptor(clientPtr.loggerPtr),
+			clientPtr.replyCachePtr.intercept,
+			MetricsInterceptor(clientPtr.metricsCollectorPtr),
+			RetryInterceptor(clientPtr.retryPolicy),
+			clientPtr.rateLimiterPtr.intercept,
+			clientPtr.attemptRequest,
+			clientPtr.encodeRequest,
+		}
+	)
+
+	return chainInvoker(append(tInterceptors, clientPtr.interceptors...), clientPtr.sendCall)
+}
+
+// sendCall - sends RequestMsgPtr and waits for the reply. The reply signature is verified and the reply decrypted before it is
+// decoded into ReplyPtr. Server errors are returned here, so ErrRateLimited or ErrUpstream can be added to RetryableErrors.
+//
+//	Customer Messages: None
+//	Errors: ErrRequiredArgumentMissing, ServerError, returned from requestWithContext, openReply, json.Unmarshal
+//	Verifications: RequestMsgPtr
+func (clientPtr *NCClient) sendCall(ctx context.Context, callPtr *Call) (errorInfo pi.ErrorInfo) {
+
+	var (
+		tSpan = trace.SpanFromContext(ctx)
+	)
+
+	if callPtr.RequestMsgPtr == nil {
+		errorInfo = pi.NewErrorInfo(pi.ErrRequiredArgumentMissing, fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, callPtr.Subject))
+		return
+	}
+
+	tSpan.SetAttributes(attribute.Int(ATTR_REQUEST_SIZE, len(callPtr.RequestMsgPtr.Data)))
+	if callPtr.ReplyMsgPtr, errorInfo = requestWithContext(
+		ctx,
+		clientPtr.natsService.ConnPtr,
+		clientPtr.natsService.InstanceName,
+		callPtr.RequestMsgPtr,
+	); errorInfo.Error != nil {
+		return
+	}
+	tSpan.SetAttributes(attribute.Int(ATTR_REPLY_SIZE, len(callPtr.ReplyMsgPtr.Data)))
+	callPtr.NextPageToken = callPtr.ReplyMsgPtr.Header.Get(HEADER_NEXT_PAGE_TOKEN)
+
+	if callPtr.ReplyData, errorInfo = openReply(
+		clientPtr.styhCustomerConfig.clientId,
+		clientPtr.styhCustomerConfig.secretKey,
+		callPtr.Subject,
+		callPtr.RequestId,
+		clientPtr.replyEnvelope,
+		callPtr.ReplyMsgPtr,
+	); errorInfo.Error != nil {
+		return
+	}
+
+	if errorInfo.Error = json.Unmarshal(callPtr.ReplyData, callPtr.ReplyPtr); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, callPtr.Subject))
+	}
+
+	return
+}
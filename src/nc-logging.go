@@ -0,0 +1,152 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math"
+	"time"
+
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+//goland:noinspection ALL
+const (
+	LOG_AWS_SETTINGS_LOADED       = "NATS Connect AWS settings loaded"
+	LOG_CONNECTED                 = "NATS Connect connection established"
+	LOG_KEY_ADDITIONAL_INFO       = "additional_info"
+	LOG_KEY_ATTEMPTS              = "attempts"
+	LOG_KEY_DURATION              = "duration"
+	LOG_KEY_ENVIRONMENT           = "environment"
+	LOG_KEY_ERROR                 = "error"
+	LOG_KEY_FILE_NAME             = "file_name"
+	LOG_KEY_FUNCTION_NAME         = "function_name"
+	LOG_KEY_IN_MEMORY_CREDENTIALS = "in_memory_credentials"
+	LOG_KEY_INSTANCE_NAME         = "instance_name"
+	LOG_KEY_LINE_NUMBER           = "line_number"
+	LOG_KEY_MESSAGE               = "message"
+	LOG_KEY_PORT                  = "port"
+	LOG_KEY_REQUEST_ID            = "request_id"
+	LOG_KEY_SERVER_ID             = "server_id"
+	LOG_KEY_STYH_CLIENT_ID        = "styh_client_id"
+	LOG_KEY_SUBJECT               = "subject"
+	LOG_KEY_TOKEN_EXPIRY          = "token_expiry"
+	LOG_KEY_URL                   = "url"
+	LOG_KEY_USERNAME              = "username"
+	LOG_LOGGED_IN                 = "NATS Connect login succeeded"
+	LOG_PARAMETERS_LOADED         = "NATS Connect parameters loaded"
+	LOG_REQUEST_COMPLETED         = "NATS Connect request completed"
+	LOG_REQUEST_FAILED            = "NATS Connect request failed"
+	LOG_TOKEN_REFRESH_FAILED      = "NATS Connect token refresh failed"
+	LOG_TOKEN_REFRESHED           = "NATS Connect tokens refreshed"
+)
+
+// LoggingInterceptor - logs the outcome of each request at debug level with the subject, request id, duration, and attempts.
+// A nil logger discards the events.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func LoggingInterceptor(loggerPtr *slog.Logger) Interceptor {
+
+	loggerPtr = newLogger(loggerPtr)
+
+	return func(ctx context.Context, callPtr *Call, next Invoker) (errorInfo pi.ErrorInfo) {
+
+		var (
+			tStart = time.Now()
+		)
+
+		errorInfo = next(ctx, callPtr)
+		logRequest(loggerPtr, callPtr, time.Since(tStart), errorInfo)
+
+		return
+	}
+}
+
+// errorInfoAttr - returns the error as a log group.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func errorInfoAttr(errorInfo pi.ErrorInfo) slog.Attr {
+
+	var (
+		tMessage = errorInfo.Message
+	)
+
+	if errorInfo.Error != nil {
+		tMessage = errorInfo.Error.Error()
+	}
+
+	return slog.Group(
+		LOG_KEY_ERROR,
+		slog.String(LOG_KEY_MESSAGE, tMessage),
+		slog.String(LOG_KEY_ADDITIONAL_INFO, errorInfo.AdditionalInfo),
+		slog.String(LOG_KEY_FILE_NAME, errorInfo.FileName),
+		slog.String(LOG_KEY_FUNCTION_NAME, errorInfo.FunctionName),
+		slog.Int(LOG_KEY_LINE_NUMBER, errorInfo.LineNumber),
+	)
+}
+
+// logRequest - logs the outcome of the request at debug level.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func logRequest(loggerPtr *slog.Logger, callPtr *Call, duration time.Duration, errorInfo pi.ErrorInfo) {
+
+	var (
+		tAttrs = []any{
+			slog.String(LOG_KEY_SUBJECT, callPtr.Subject),
+			slog.String(LOG_KEY_REQUEST_ID, callPtr.RequestId),
+			slog.Duration(LOG_KEY_DURATION, duration),
+			slog.Int(LOG_KEY_ATTEMPTS, callPtr.Attempts),
+		}
+	)
+
+	if errorInfo.Error != nil {
+		loggerPtr.Debug(LOG_REQUEST_FAILED, append(tAttrs, errorInfoAttr(errorInfo))...)
+		return
+	}
+
+	loggerPtr.Debug(LOG_REQUEST_COMPLETED, tAttrs...)
+}
+
+// newLogger - returns the logger, or a logger that discards everything when it is nil. The client doesn't write to
+// standard output unless a logger is provided with WithLogger.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func newLogger(loggerPtr *slog.Logger) *slog.Logger {
+
+	if loggerPtr != nil {
+		return loggerPtr
+	}
+
+	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.Level(math.MaxInt)}))
+}
@@ -0,0 +1,327 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+//goland:noinspection ALL
+const (
+	ERROR_CLASS_CANCELED            = "canceled"
+	ERROR_CLASS_CIRCUIT_OPEN        = "circuit_open"
+	ERROR_CLASS_CLIENT_RATE_LIMITED = "client_rate_limited"
+	ERROR_CLASS_CONNECTION          = "connection"
+	ERROR_CLASS_NO_RESPONDERS       = "no_responders"
+	ERROR_CLASS_NOT_FOUND           = "not_found"
+	ERROR_CLASS_OTHER               = "other"
+	ERROR_CLASS_RATE_LIMITED        = "rate_limited"
+	ERROR_CLASS_REPLY_REJECTED      = "reply_rejected"
+	ERROR_CLASS_SERVER              = "server"
+	ERROR_CLASS_TIMEOUT             = "timeout"
+	ERROR_CLASS_UNAUTHORIZED        = "unauthorized"
+	ERROR_CLASS_UPSTREAM            = "upstream"
+	LABEL_ERROR_CLASS               = "error_class"
+	LABEL_OUTCOME                   = "outcome"
+	LABEL_STATE                     = "state"
+	LABEL_SUBJECT                   = "subject"
+	METRICS_NAMESPACE               = "nats_connect"
+	OUTCOME_ERROR                   = "error"
+	OUTCOME_SUCCESS                 = "success"
+)
+
+// MetricsCollector - Prometheus metrics for the client's requests and connection. Register it with a Prometheus registry
+// and pass it to the client with WithMetrics. Use one collector per client.
+type MetricsCollector struct {
+	connectionReconnects *prometheus.Desc
+	connectionState      *prometheus.Desc
+	connPtr              *nats.Conn
+	mutex                sync.RWMutex
+	replySize            *prometheus.HistogramVec
+	requestDuration      *prometheus.HistogramVec
+	requestErrors        *prometheus.CounterVec
+	requestRetries       *prometheus.CounterVec
+	requestSize          *prometheus.HistogramVec
+}
+
+// connectionStates - the states reported by the connection state gauge.
+var connectionStates = []nats.Status{
+	nats.CONNECTED,
+	nats.CONNECTING,
+	nats.DISCONNECTED,
+	nats.RECONNECTING,
+	nats.DRAINING_SUBS,
+	nats.DRAINING_PUBS,
+	nats.CLOSED,
+}
+
+// MetricsInterceptor - records the duration, outcome, retries, and error class of each request, and the sizes of the last
+// attempt. A nil collector records nothing.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func MetricsInterceptor(collectorPtr *MetricsCollector) Interceptor {
+
+	return func(ctx context.Context, callPtr *Call, next Invoker) (errorInfo pi.ErrorInfo) {
+
+		var (
+			tReplySize = -1
+			tStart     = time.Now()
+		)
+
+		errorInfo = next(ctx, callPtr)
+		if callPtr.RequestMsgPtr != nil {
+			if callPtr.ReplyMsgPtr != nil {
+				tReplySize = len(callPtr.ReplyMsgPtr.Data)
+			}
+			collectorPtr.observeAttempt(callPtr.Subject, len(callPtr.RequestMsgPtr.Data), tReplySize)
+		}
+		collectorPtr.observeRequest(callPtr.Subject, time.Since(tStart), callPtr.Attempts, errorInfo.Error)
+
+		return
+	}
+}
+
+// NewMetricsCollector - creates the collector. The metric names start with nats_connect_.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func NewMetricsCollector() (collectorPtr *MetricsCollector) {
+
+	var (
+		tSizeBuckets = prometheus.ExponentialBuckets(64, 4, 8) // 64 bytes to 1 MiB
+	)
+
+	return &MetricsCollector{
+		connectionReconnects: prometheus.NewDesc(
+			prometheus.BuildFQName(METRICS_NAMESPACE, "connection", "reconnects_total"),
+			"Number of times the connection to NATS Connect was re-established.",
+			nil,
+			nil,
+		),
+		connectionState: prometheus.NewDesc(
+			prometheus.BuildFQName(METRICS_NAMESPACE, "connection", "state"),
+			"State of the connection to NATS Connect. The current state is 1 and the other states are 0.",
+			[]string{LABEL_STATE},
+			nil,
+		),
+		replySize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: METRICS_NAMESPACE,
+				Subsystem: "reply",
+				Name:      "size_bytes",
+				Help:      "Size of the reply data received in the last attempt.",
+				Buckets:   tSizeBuckets,
+			},
+			[]string{LABEL_SUBJECT},
+		),
+		requestDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: METRICS_NAMESPACE,
+				Subsystem: "request",
+				Name:      "duration_seconds",
+				Help:      "Time from sending the request until the reply is decoded, including retries.",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{LABEL_SUBJECT, LABEL_OUTCOME},
+		),
+		requestErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: METRICS_NAMESPACE,
+				Subsystem: "request",
+				Name:      "errors_total",
+				Help:      "Number of failed requests by error class.",
+			},
+			[]string{LABEL_SUBJECT, LABEL_ERROR_CLASS},
+		),
+		requestRetries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: METRICS_NAMESPACE,
+				Subsystem: "request",
+				Name:      "retries_total",
+				Help:      "Number of times a request was retried.",
+			},
+			[]string{LABEL_SUBJECT},
+		),
+		requestSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: METRICS_NAMESPACE,
+				Subsystem: "request",
+				Name:      "size_bytes",
+				Help:      "Size of the encrypted request data sent in the last attempt.",
+				Buckets:   tSizeBuckets,
+			},
+			[]string{LABEL_SUBJECT},
+		),
+	}
+}
+
+// Collect - implements prometheus.Collector.
+func (collectorPtr *MetricsCollector) Collect(metrics chan<- prometheus.Metric) {
+
+	var (
+		tConnPtr *nats.Conn
+		tStatus  = nats.DISCONNECTED
+		tValue   float64
+	)
+
+	collectorPtr.replySize.Collect(metrics)
+	collectorPtr.requestDuration.Collect(metrics)
+	collectorPtr.requestErrors.Collect(metrics)
+	collectorPtr.requestRetries.Collect(metrics)
+	collectorPtr.requestSize.Collect(metrics)
+
+	collectorPtr.mutex.RLock()
+	tConnPtr = collectorPtr.connPtr
+	collectorPtr.mutex.RUnlock()
+
+	if tConnPtr != nil {
+		tStatus = tConnPtr.Status()
+		metrics <- prometheus.MustNewConstMetric(collectorPtr.connectionReconnects, prometheus.CounterValue, float64(tConnPtr.Stats().Reconnects))
+	}
+	for _, state := range connectionStates {
+		tValue = 0
+		if state == tStatus {
+			tValue = 1
+		}
+		metrics <- prometheus.MustNewConstMetric(collectorPtr.connectionState, prometheus.GaugeValue, tValue, state.String())
+	}
+}
+
+// Describe - implements prometheus.Collector.
+func (collectorPtr *MetricsCollector) Describe(descriptions chan<- *prometheus.Desc) {
+
+	collectorPtr.replySize.Describe(descriptions)
+	collectorPtr.requestDuration.Describe(descriptions)
+	collectorPtr.requestErrors.Describe(descriptions)
+	collectorPtr.requestRetries.Describe(descriptions)
+	collectorPtr.requestSize.Describe(descriptions)
+	descriptions <- collectorPtr.connectionReconnects
+	descriptions <- collectorPtr.connectionState
+}
+
+// getErrorClass - returns the error class label for the error.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func getErrorClass(err error) string {
+
+	switch {
+	case errors.Is(err, ErrRequestTimeout), errors.Is(err, nats.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return ERROR_CLASS_TIMEOUT
+	case errors.Is(err, context.Canceled):
+		return ERROR_CLASS_CANCELED
+	case errors.Is(err, nats.ErrNoResponders):
+		return ERROR_CLASS_NO_RESPONDERS
+	case errors.Is(err, ErrCircuitOpen):
+		return ERROR_CLASS_CIRCUIT_OPEN
+	case errors.Is(err, ErrNotFound):
+		return ERROR_CLASS_NOT_FOUND
+	case errors.Is(err, ErrUnauthorized):
+		return ERROR_CLASS_UNAUTHORIZED
+	case errors.Is(err, ErrClientRateLimited):
+		return ERROR_CLASS_CLIENT_RATE_LIMITED
+	case errors.Is(err, ErrRateLimited):
+		return ERROR_CLASS_RATE_LIMITED
+	case errors.Is(err, ErrUpstream):
+		return ERROR_CLASS_UPSTREAM
+	case errors.Is(err, ErrServerError):
+		return ERROR_CLASS_SERVER
+	case errors.Is(err, ErrReplySignatureInvalid), errors.Is(err, ErrReplyNotEncrypted):
+		return ERROR_CLASS_REPLY_REJECTED
+	case errors.Is(err, nats.ErrConnectionClosed), errors.Is(err, nats.ErrConnectionDraining), errors.Is(err, nats.ErrConnectionReconnecting):
+		return ERROR_CLASS_CONNECTION
+	default:
+		return ERROR_CLASS_OTHER
+	}
+}
+
+// observeAttempt - records the request and reply sizes for one attempt. A reply size below zero means there was no reply.
+// Safe to call on a nil collector.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (collectorPtr *MetricsCollector) observeAttempt(subject string, requestSize int, replySize int) {
+
+	if collectorPtr == nil {
+		return
+	}
+
+	collectorPtr.requestSize.WithLabelValues(subject).Observe(float64(requestSize))
+	if replySize >= 0 {
+		collectorPtr.replySize.WithLabelValues(subject).Observe(float64(replySize))
+	}
+}
+
+// observeRequest - records the duration, outcome, retries, and error class of a request. Safe to call on a nil collector.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (collectorPtr *MetricsCollector) observeRequest(subject string, duration time.Duration, attempts int, err error) {
+
+	if collectorPtr == nil {
+		return
+	}
+
+	if attempts > 1 {
+		collectorPtr.requestRetries.WithLabelValues(subject).Add(float64(attempts - 1))
+	}
+	if err != nil {
+		collectorPtr.requestDuration.WithLabelValues(subject, OUTCOME_ERROR).Observe(duration.Seconds())
+		collectorPtr.requestErrors.WithLabelValues(subject, getErrorClass(err)).Inc()
+		return
+	}
+
+	collectorPtr.requestDuration.WithLabelValues(subject, OUTCOME_SUCCESS).Observe(duration.Seconds())
+}
+
+// setConnection - sets the connection reported by the connection gauges. Safe to call on a nil collector.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (collectorPtr *MetricsCollector) setConnection(connPtr *nats.Conn) {
+
+	if collectorPtr == nil {
+		return
+	}
+
+	collectorPtr.mutex.Lock()
+	defer collectorPtr.mutex.Unlock()
+
+	collectorPtr.connPtr = connPtr
+}
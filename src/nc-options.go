@@ -0,0 +1,370 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+//goland:noinspection ALL
+const (
+	DEFAULT_REQUEST_TIMEOUT = 2 * time.Second
+)
+
+// Credentials - the NATS Connect account information. Named fields keep the values from being swapped.
+type Credentials struct {
+	Password     string
+	SecretKey    string
+	STYHClientId string
+	Username     string
+}
+
+// CallOption - configures a single request made with one of the Synaida*Ctx methods.
+type CallOption func(callOptionsPtr *callOptions)
+
+// Option - configures the client built by NewNCClientWithOptions.
+type Option func(optionsPtr *clientOptions)
+
+type callOptions struct {
+	cacheBypass    bool
+	pageSize       int
+	pageToken      string
+	replyInfoPtr   *ReplyInfo
+	requestId      string
+	retryPolicyPtr *RetryPolicy
+	timeout        time.Duration
+}
+
+type clientOptions struct {
+	circuitBreaker      CircuitBreakerSettings
+	cognitoClientSecret string
+	compression         CompressionSettings
+	configFileFQN       string
+	connectionHandlers  ConnectionHandlers
+	credentials         Credentials
+	environment         string
+	inMemoryCredentials bool
+	interceptors        []Interceptor
+	loggerPtr           *slog.Logger
+	metricsCollectorPtr *MetricsCollector
+	rateLimitPtr        *RateLimitSettings
+	replyCache          ReplyCacheSettings
+	replyEnvelope       ReplyEnvelopeSettings
+	requestTimeout      time.Duration
+	retryPolicy         RetryPolicy
+	subjectTimeouts     map[string]time.Duration
+	tempDirectory       string
+	tracerProvider      trace.TracerProvider
+}
+
+// WithAsyncErrorHandler - sets the callback for connection errors that don't belong to a request, such as a slow consumer.
+func WithAsyncErrorHandler(handler func(subject string, err error)) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.connectionHandlers.AsyncError = handler
+	}
+}
+
+// WithCallCacheBypass - sends the request even when a cached reply is available. The new reply replaces the cached one.
+func WithCallCacheBypass() CallOption {
+
+	return func(callOptionsPtr *callOptions) {
+		callOptionsPtr.cacheBypass = true
+	}
+}
+
+// WithCallPage - requests one page of a List* reply. A page size of zero uses the server default, and an empty token requests
+// the first page. The token for the next page is returned in ReplyInfo.NextPageToken. NewPager does this for you.
+func WithCallPage(pageSize int, pageToken string) CallOption {
+
+	return func(callOptionsPtr *callOptions) {
+		callOptionsPtr.pageSize = pageSize
+		callOptionsPtr.pageToken = pageToken
+	}
+}
+
+// WithCallReplyInfo - fills in the reply information, such as the request id, when the request completes. It is filled in
+// when the request fails, so the request id can be given to STY Holdings support.
+func WithCallReplyInfo(replyInfoPtr *ReplyInfo) CallOption {
+
+	return func(callOptionsPtr *callOptions) {
+		callOptionsPtr.replyInfoPtr = replyInfoPtr
+	}
+}
+
+// WithCallRequestId - sets the request id sent in the NC-Request-Id header. The default is a new unique id for each request.
+func WithCallRequestId(requestId string) CallOption {
+
+	return func(callOptionsPtr *callOptions) {
+		callOptionsPtr.requestId = requestId
+	}
+}
+
+// WithCallRetryPolicy - sets the retry policy for this request. It replaces the client retry policy and also applies to requests
+// that change data, so only use it when the request is safe to repeat.
+func WithCallRetryPolicy(policy RetryPolicy) CallOption {
+
+	return func(callOptionsPtr *callOptions) {
+		callOptionsPtr.retryPolicyPtr = &policy
+	}
+}
+
+// WithCallTimeout - sets how long each attempt of this request waits for a reply. It replaces the subject and client timeouts.
+// If the context has an earlier deadline, the context deadline is used.
+func WithCallTimeout(timeout time.Duration) CallOption {
+
+	return func(callOptionsPtr *callOptions) {
+		callOptionsPtr.timeout = timeout
+	}
+}
+
+// WithCircuitBreaker - sets the per-subject circuit breaker settings. The default is DefaultCircuitBreakerSettings. A
+// FailureThreshold of zero turns the circuit breakers off.
+func WithCircuitBreaker(settings CircuitBreakerSettings) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.circuitBreaker = settings
+	}
+}
+
+// WithClosedHandler - sets the callback for when the connection is closed and will not reconnect.
+func WithClosedHandler(handler func()) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.connectionHandlers.Closed = handler
+	}
+}
+
+// WithCompression - compresses requests larger than the threshold before they are encrypted, and tells the server the
+// client reads compressed replies. Compressed replies are decompressed before they are decoded either way.
+func WithCompression(settings CompressionSettings) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.compression = settings
+	}
+}
+
+// WithCognitoClientSecret - sets the secret of the Cognito app client. It is only needed when the app client has a secret, and
+// is used to sign the token refresh requests.
+func WithCognitoClientSecret(clientSecret string) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.cognitoClientSecret = clientSecret
+	}
+}
+
+// WithConfigFile - loads the credentials, environment, and temporary directory from the configuration file.
+// When a configuration file is provided, it replaces the values from WithCredentials, WithEnvironment, and WithTempDir.
+func WithConfigFile(configFileFQN string) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.configFileFQN = configFileFQN
+	}
+}
+
+// WithCredentials - sets the NATS Connect client id, username, password, and secret key.
+func WithCredentials(credentials Credentials) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.credentials = credentials
+	}
+}
+
+// WithDisconnectErrHandler - sets the callback for when the connection is lost. The error is nil when the client disconnected.
+func WithDisconnectErrHandler(handler func(err error)) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.connectionHandlers.DisconnectErr = handler
+	}
+}
+
+// WithEnvironment - sets the NATS Connect environment: production, development, or local.
+func WithEnvironment(environment string) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.environment = environment
+	}
+}
+
+// WithInMemoryCredentials - keeps the NATS credentials and TLS information in memory instead of writing them to the temporary
+// directory. WithTempDir is not needed, which allows read-only file systems and serverless runtimes.
+func WithInMemoryCredentials() Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.inMemoryCredentials = true
+	}
+}
+
+// WithInterceptors - adds interceptors to the request chain in the order given. They run inside the logging, reply cache,
+// metrics, retry, and rate limit interceptors, so they are called for each attempt after the request is marshalled and encrypted. Calling
+// WithInterceptors more than once appends to the chain.
+func WithInterceptors(interceptors ...Interceptor) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.interceptors = append(optionsPtr.interceptors, interceptors...)
+	}
+}
+
+// WithLogger - sends the client's log events to the logger. Connecting, logging in, loading parameters, and each request are
+// logged at debug level, and background token refresh failures at warn level. Without a logger, nothing is logged.
+func WithLogger(loggerPtr *slog.Logger) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.loggerPtr = loggerPtr
+	}
+}
+
+// WithMetrics - records request and connection metrics in the collector. Register the collector with a Prometheus registry.
+func WithMetrics(collectorPtr *MetricsCollector) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.metricsCollectorPtr = collectorPtr
+	}
+}
+
+// WithRateLimit - turns on the client rate limiter. Requests wait for, or fail fast without, a token from their SaaS key's
+// bucket and their subject's bucket. Use CalibrateRateLimit to set a SaaS key's limit from its Synadia team limits.
+func WithRateLimit(settings RateLimitSettings) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.rateLimitPtr = &settings
+	}
+}
+
+// WithReconnectHandler - sets the callback for when the connection is restored. The URL has any credentials removed.
+func WithReconnectHandler(handler func(connectedURL string)) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.connectionHandlers.Reconnect = handler
+	}
+}
+
+// WithReplyCache - caches the replies to read-only requests, such as SynaidaGetVersion, in memory. Identical requests on a
+// subject get the cached reply until its TTL passes. Use InvalidateCache to remove replies early.
+func WithReplyCache(settings ReplyCacheSettings) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.replyCache = settings
+	}
+}
+
+// WithReplyEnvelope - requires replies to be encrypted, signed, or both. The server is told in the request header, and replies
+// without the required protections are rejected. Without it, unsigned replies are accepted.
+func WithReplyEnvelope(settings ReplyEnvelopeSettings) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.replyEnvelope = settings
+	}
+}
+
+// WithRetryPolicy - sets the retry policy for read-only requests, such as SynaidaGetTeam. The default is DefaultRetryPolicy.
+// Use NoRetryPolicy to turn off retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.retryPolicy = policy
+	}
+}
+
+// WithSubjectTimeout - sets how long each attempt of a request on the subject waits for a reply. It replaces the client timeout
+// for the subject, for example, ctv.SUB_SYNADIA_LIST_SYSTEMS for large teams. If the context has an earlier deadline, the
+// context deadline is used.
+func WithSubjectTimeout(subject string, timeout time.Duration) Option {
+
+	return func(optionsPtr *clientOptions) {
+		if optionsPtr.subjectTimeouts == nil {
+			optionsPtr.subjectTimeouts = make(map[string]time.Duration)
+		}
+		optionsPtr.subjectTimeouts[subject] = timeout
+	}
+}
+
+// WithTempDir - sets the directory where the NATS credentials and TLS files are written.
+func WithTempDir(tempDirectory string) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.tempDirectory = tempDirectory
+	}
+}
+
+// WithTracerProvider - creates a span for each request using the tracer provider. The W3C trace context is sent in the
+// request header either way, so a span started by the caller is continued by the NATS Connect backend.
+func WithTracerProvider(tracerProvider trace.TracerProvider) Option {
+
+	return func(optionsPtr *clientOptions) {
+		optionsPtr.tracerProvider = tracerProvider
+	}
+}
+
+// WithTimeout - sets how long each attempt of a request waits for a reply. If the context has an earlier deadline, the
+// context deadline is used. This is the client default, WithSubjectTimeout and WithCallTimeout override it. The default is DEFAULT_REQUEST_TIMEOUT. A timeout of zero or less
+// is ignored and the default is kept.
+func WithTimeout(timeout time.Duration) Option {
+
+	return func(optionsPtr *clientOptions) {
+		if timeout <= 0 {
+			return
+		}
+		optionsPtr.requestTimeout = timeout
+	}
+}
+
+// newCallOptions - applies the call options.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func newCallOptions(callOpts ...CallOption) (options callOptions) {
+
+	for _, callOpt := range callOpts {
+		if callOpt != nil {
+			callOpt(&options)
+		}
+	}
+
+	return
+}
+
+// newClientOptions - applies the options over the defaults.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func newClientOptions(opts ...Option) (options clientOptions) {
+
+	options.circuitBreaker = DefaultCircuitBreakerSettings()
+	options.requestTimeout = DEFAULT_REQUEST_TIMEOUT
+	options.retryPolicy = DefaultRetryPolicy()
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+
+	return
+}
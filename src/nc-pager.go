@@ -0,0 +1,406 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+
+	ctv "github.com/sty-holdings/constant-type-vars-go/v2024"
+	ncs "github.com/sty-holdings/nats-connect-shared/v2024"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+//goland:noinspection ALL
+const (
+	HEADER_NEXT_PAGE_TOKEN = "NC-Next-Page-Token" // Reply header. The token for the next page. Missing on the last page.
+	HEADER_PAGE_SIZE       = "NC-Page-Size"       // Request header. The most items the reply should have.
+	HEADER_PAGE_TOKEN      = "NC-Page-Token"      // Request header. The page to return. Missing for the first page.
+)
+
+// Pager - iterates over the items of a List* request, fetching the following pages as needed. Call Next until it returns
+// false, then check Err.
+type Pager[Request any, Reply any, Item any] struct {
+	clientPtr     *NCClient
+	count         int
+	endpoint      Endpoint[Request, Reply]
+	done          bool
+	errorInfo     pi.ErrorInfo
+	index         int
+	item          Item
+	items         []Item
+	itemsFunc     func(reply Reply) []Item
+	nextPageToken string
+	options       pagerOptions
+	request       Request
+}
+
+// PagerOption - configures a pager created by NewPager.
+type PagerOption func(pagerOptionsPtr *pagerOptions)
+
+type pagerOptions struct {
+	callOptions []CallOption
+	limit       int
+	pageSize    int
+}
+
+// WithPageSize - sets how many items are requested for each page. The default is the server default.
+func WithPageSize(pageSize int) PagerOption {
+
+	return func(pagerOptionsPtr *pagerOptions) {
+		pagerOptionsPtr.pageSize = pageSize
+	}
+}
+
+// WithPagerCallOptions - applies the call options to every page request.
+func WithPagerCallOptions(callOptions ...CallOption) PagerOption {
+
+	return func(pagerOptionsPtr *pagerOptions) {
+		pagerOptionsPtr.callOptions = append(pagerOptionsPtr.callOptions, callOptions...)
+	}
+}
+
+// WithItemLimit - stops the pager after the number of items. The last page is requested with a smaller page size when needed.
+// Zero means no limit.
+func WithItemLimit(limit int) PagerOption {
+
+	return func(pagerOptionsPtr *pagerOptions) {
+		pagerOptionsPtr.limit = limit
+	}
+}
+
+// NewPager - returns a pager for the List* endpoint, such as SynaidaListTeamsEndpoint. itemsFunc returns the items of one
+// page, usually reply.Response.Items. The Synaida*Pager methods, such as SynaidaListTeamsPager, already know how to get the
+// items. No request is sent until Next is called.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func NewPager[Request any, Reply any, Item any](
+	clientPtr *NCClient,
+	requestEndpoint Endpoint[Request, Reply],
+	request Request,
+	itemsFunc func(reply Reply) []Item,
+	pagerOpts ...PagerOption,
+) (pagerPtr *Pager[Request, Reply, Item]) {
+
+	pagerPtr = &Pager[Request, Reply, Item]{
+		clientPtr: clientPtr,
+		endpoint:  requestEndpoint,
+		itemsFunc: itemsFunc,
+		request:   request,
+	}
+	for _, pagerOpt := range pagerOpts {
+		if pagerOpt != nil {
+			pagerOpt(&pagerPtr.options)
+		}
+	}
+
+	return
+}
+
+// SynaidaListAccountsPager - returns a pager over the accounts. No request is sent until Next is called.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) SynaidaListAccountsPager(
+	request ncs.ListAccountsRequest,
+	pagerOpts ...PagerOption,
+) *Pager[ncs.ListAccountsRequest, ncs.ListAccountsReply, ncs.Item] {
+
+	return NewPager(
+		clientPtr,
+		SynaidaListAccountsEndpoint,
+		request,
+		func(reply ncs.ListAccountsReply) []ncs.Item { return reply.Response.Items },
+		pagerOpts...,
+	)
+}
+
+// SynaidaListInfoAppUsersTeamPager - returns a pager over the info app users of the team. No request is sent until Next is called.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) SynaidaListInfoAppUsersTeamPager(
+	request ncs.ListInfoAppUserTeamRequest,
+	pagerOpts ...PagerOption,
+) *Pager[ncs.ListInfoAppUserTeamRequest, ncs.ListInfoAppUsersTeamReply, ncs.Item] {
+
+	return NewPager(
+		clientPtr,
+		SynaidaListInfoAppUsersTeamEndpoint,
+		request,
+		func(reply ncs.ListInfoAppUsersTeamReply) []ncs.Item { return reply.Response.Items },
+		pagerOpts...,
+	)
+}
+
+// SynaidaListNATSUsersPager - returns a pager over the NATS users. No request is sent until Next is called.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) SynaidaListNATSUsersPager(
+	request ncs.ListNATSUsersRequest,
+	pagerOpts ...PagerOption,
+) *Pager[ncs.ListNATSUsersRequest, ncs.ListNATSUsersReply, ncs.Item] {
+
+	return NewPager(
+		clientPtr,
+		SynaidaListNATSUsersEndpoint,
+		request,
+		func(reply ncs.ListNATSUsersReply) []ncs.Item { return reply.Response.Items },
+		pagerOpts...,
+	)
+}
+
+// SynaidaListPersonalAccessTokensPager - returns a pager over the personal access tokens. No request is sent until Next is called.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) SynaidaListPersonalAccessTokensPager(
+	request ncs.ListPersonalAccessTokensRequest,
+	pagerOpts ...PagerOption,
+) *Pager[ncs.ListPersonalAccessTokensRequest, ncs.ListPersonalAccessTokensReply, ncs.Item] {
+
+	return NewPager(
+		clientPtr,
+		SynaidaListPersonalAccessTokensEndpoint,
+		request,
+		func(reply ncs.ListPersonalAccessTokensReply) []ncs.Item { return reply.Response.Items },
+		pagerOpts...,
+	)
+}
+
+// SynaidaListSystemAccountInfoPager - returns a pager over the system accounts. No request is sent until Next is called.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) SynaidaListSystemAccountInfoPager(
+	request ncs.ListSystemAccountInfoRequest,
+	pagerOpts ...PagerOption,
+) *Pager[ncs.ListSystemAccountInfoRequest, ncs.ListSystemAccountInfoReply, ncs.Item] {
+
+	return NewPager(
+		clientPtr,
+		SynaidaListSystemAccountInfoEndpoint,
+		request,
+		func(reply ncs.ListSystemAccountInfoReply) []ncs.Item { return reply.Response.Items },
+		pagerOpts...,
+	)
+}
+
+// SynaidaListSystemServerInfoPager - returns a pager over the system servers. No request is sent until Next is called.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) SynaidaListSystemServerInfoPager(
+	request ncs.ListSystemServerInfoRequest,
+	pagerOpts ...PagerOption,
+) *Pager[ncs.ListSystemServerInfoRequest, ncs.ListSystemServerInfoReply, ncs.Item] {
+
+	return NewPager(
+		clientPtr,
+		SynaidaListSystemServerInfoEndpoint,
+		request,
+		func(reply ncs.ListSystemServerInfoReply) []ncs.Item { return reply.Response.Items },
+		pagerOpts...,
+	)
+}
+
+// SynaidaListSystemsPager - returns a pager over the systems. No request is sent until Next is called.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) SynaidaListSystemsPager(
+	request ncs.ListSystemsRequest,
+	pagerOpts ...PagerOption,
+) *Pager[ncs.ListSystemsRequest, ncs.ListSystemsReply, ncs.Item] {
+
+	return NewPager(
+		clientPtr,
+		SynaidaListSystemsEndpoint,
+		request,
+		func(reply ncs.ListSystemsReply) []ncs.Item { return reply.Response.Items },
+		pagerOpts...,
+	)
+}
+
+// SynaidaListTeamServerAccountsPager - returns a pager over the team server accounts. No request is sent until Next is called.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) SynaidaListTeamServerAccountsPager(
+	request ncs.ListTeamServerAccountsRequest,
+	pagerOpts ...PagerOption,
+) *Pager[ncs.ListTeamServerAccountsRequest, ncs.ListTeamServerAccountsReply, ncs.Item] {
+
+	return NewPager(
+		clientPtr,
+		SynaidaListTeamServerAccountsEndpoint,
+		request,
+		func(reply ncs.ListTeamServerAccountsReply) []ncs.Item { return reply.Response.Items },
+		pagerOpts...,
+	)
+}
+
+// SynaidaListTeamsPager - returns a pager over the teams. No request is sent until Next is called.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) SynaidaListTeamsPager(
+	request ncs.ListTeamsRequest,
+	pagerOpts ...PagerOption,
+) *Pager[ncs.ListTeamsRequest, ncs.ListTeamsReply, ncs.Item] {
+
+	return NewPager(
+		clientPtr,
+		SynaidaListTeamsEndpoint,
+		request,
+		func(reply ncs.ListTeamsReply) []ncs.Item { return reply.Response.Items },
+		pagerOpts...,
+	)
+}
+
+// All - returns the remaining items. The items read before an error are returned with it.
+//
+//	Customer Messages: None
+//	Errors: returned from Do
+//	Verifications: None
+func (pagerPtr *Pager[Request, Reply, Item]) All(ctx context.Context) (items []Item, errorInfo pi.ErrorInfo) {
+
+	for pagerPtr.Next(ctx) {
+		items = append(items, pagerPtr.item)
+	}
+
+	return items, pagerPtr.errorInfo
+}
+
+// Err - returns the error that stopped the pager. It is empty when the pager stopped after the last item.
+//
+//	Customer Messages: None
+//	Errors: returned from Do
+//	Verifications: None
+func (pagerPtr *Pager[Request, Reply, Item]) Err() pi.ErrorInfo {
+
+	return pagerPtr.errorInfo
+}
+
+// Item - returns the item Next moved to.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (pagerPtr *Pager[Request, Reply, Item]) Item() Item {
+
+	return pagerPtr.item
+}
+
+// Next - moves to the next item, fetching the next page when the current one is used up. False is returned after the last
+// item, when the item limit is reached, or when a page request fails.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (pagerPtr *Pager[Request, Reply, Item]) Next(ctx context.Context) bool {
+
+	if pagerPtr.options.limit > 0 && pagerPtr.count >= pagerPtr.options.limit {
+		return false
+	}
+
+	for pagerPtr.index >= len(pagerPtr.items) {
+		if pagerPtr.done || pagerPtr.errorInfo.Error != nil {
+			return false
+		}
+		pagerPtr.fetch(ctx)
+	}
+
+	pagerPtr.item = pagerPtr.items[pagerPtr.index]
+	pagerPtr.index++
+	pagerPtr.count++
+
+	return true
+}
+
+// fetch - requests the next page. The pager is done when the reply has no next page token, or the token didn't change.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (pagerPtr *Pager[Request, Reply, Item]) fetch(ctx context.Context) {
+
+	var (
+		tPageSize  = pagerPtr.options.pageSize
+		tReply     Reply
+		tReplyInfo ReplyInfo
+	)
+
+	if pagerPtr.options.limit > 0 && (tPageSize <= 0 || tPageSize > pagerPtr.options.limit-pagerPtr.count) {
+		tPageSize = pagerPtr.options.limit - pagerPtr.count
+	}
+
+	if tReply, pagerPtr.errorInfo = Do(
+		ctx,
+		pagerPtr.clientPtr,
+		pagerPtr.endpoint,
+		pagerPtr.request,
+		append(
+			append([]CallOption{}, pagerPtr.options.callOptions...),
+			WithCallPage(tPageSize, pagerPtr.nextPageToken),
+			WithCallReplyInfo(&tReplyInfo),
+		)...,
+	); pagerPtr.errorInfo.Error != nil {
+		return
+	}
+
+	pagerPtr.index = 0
+	pagerPtr.items = pagerPtr.itemsFunc(tReply)
+	pagerPtr.done = tReplyInfo.NextPageToken == ctv.VAL_EMPTY || tReplyInfo.NextPageToken == pagerPtr.nextPageToken
+	pagerPtr.nextPageToken = tReplyInfo.NextPageToken
+}
+
+// setPageHeaders - adds the page size and page token set with WithCallPage to the request headers.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func setPageHeaders(options callOptions, requestMsgPtr *nats.Msg) {
+
+	if options.pageSize > 0 {
+		requestMsgPtr.Header.Set(HEADER_PAGE_SIZE, strconv.Itoa(options.pageSize))
+	}
+	if options.pageToken != ctv.VAL_EMPTY {
+		requestMsgPtr.Header.Set(HEADER_PAGE_TOKEN, options.pageToken)
+	}
+}
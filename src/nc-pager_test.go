@@ -0,0 +1,173 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	ncs "github.com/sty-holdings/nats-connect-shared/v2024"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+func TestPager(tPtr *testing.T) {
+
+	type page struct {
+		size  string
+		token string
+	}
+
+	tests := []struct {
+		name      string
+		failToken string
+		stuck     bool
+		pagerOpts []PagerOption
+		wantItems []string
+		wantPages []page
+		wantError error
+	}{
+		{
+			name:      "Positive Case: Server default page size.",
+			wantItems: []string{"T0", "T1", "T2", "T3", "T4", "T5", "T6"},
+			wantPages: []page{{"", ""}, {"", "3"}, {"", "6"}},
+		},
+		{
+			name:      "Positive Case: Page size.",
+			pagerOpts: []PagerOption{WithPageSize(2)},
+			wantItems: []string{"T0", "T1", "T2", "T3", "T4", "T5", "T6"},
+			wantPages: []page{{"2", ""}, {"2", "2"}, {"2", "4"}, {"2", "6"}},
+		},
+		{
+			name:      "Positive Case: Item limit asks for a smaller last page.",
+			pagerOpts: []PagerOption{WithPageSize(3), WithItemLimit(5)},
+			wantItems: []string{"T0", "T1", "T2", "T3", "T4"},
+			wantPages: []page{{"3", ""}, {"2", "3"}},
+		},
+		{
+			name:      "Positive Case: Item limit without a page size.",
+			pagerOpts: []PagerOption{WithItemLimit(2)},
+			wantItems: []string{"T0", "T1"},
+			wantPages: []page{{"2", ""}},
+		},
+		{
+			name:      "Positive Case: Unchanged next page token stops the pager.",
+			stuck:     true,
+			wantItems: []string{"T0", "T1", "T2", "T0", "T1", "T2"},
+			wantPages: []page{{"", ""}, {"", "STUCK"}},
+		},
+		{
+			name:      "Negative Case: Page request fails.",
+			failToken: "3",
+			wantItems: []string{"T0", "T1", "T2"},
+			wantPages: []page{{"", ""}, {"", "3"}},
+			wantError: ErrNotFound,
+		},
+	}
+
+	for _, ts := range tests {
+		tPtr.Run(ts.name, func(t *testing.T) {
+			var (
+				tErrorInfo pi.ErrorInfo
+				tIds       []string
+				tItems     []ncs.Item
+				tPages     []page
+				tPagerPtr  *Pager[ncs.ListTeamsRequest, ncs.ListTeamsReply, ncs.Item]
+			)
+
+			tPagerPtr = newMockClient(func(ctx context.Context, callPtr *Call, next Invoker) (errorInfo pi.ErrorInfo) {
+				tPage := page{
+					size:  callPtr.RequestMsgPtr.Header.Get(HEADER_PAGE_SIZE),
+					token: callPtr.RequestMsgPtr.Header.Get(HEADER_PAGE_TOKEN),
+				}
+				tPages = append(tPages, tPage)
+				if tPage.token != "" && tPage.token == ts.failToken {
+					return pi.NewErrorInfo(ErrNotFound, fmt.Sprintf("page %v", tPage.token))
+				}
+				return mockListTeams(callPtr, tPage.size, tPage.token, ts.stuck)
+			}).SynaidaListTeamsPager(ncs.ListTeamsRequest{}, ts.pagerOpts...)
+
+			tItems, tErrorInfo = tPagerPtr.All(context.Background())
+			for _, item := range tItems {
+				tIds = append(tIds, item.Id)
+			}
+			if reflect.DeepEqual(tIds, ts.wantItems) == false {
+				t.Errorf("%v: got items %v, want %v", ts.name, tIds, ts.wantItems)
+			}
+			if reflect.DeepEqual(tPages, ts.wantPages) == false {
+				t.Errorf("%v: got pages %v, want %v", ts.name, tPages, ts.wantPages)
+			}
+			if errors.Is(tErrorInfo.Error, ts.wantError) == false || (ts.wantError == nil && tErrorInfo.Error != nil) {
+				t.Errorf("%v: got error %v, want %v", ts.name, tErrorInfo.Error, ts.wantError)
+			}
+			if tPagerPtr.Err().Error != tErrorInfo.Error {
+				t.Errorf("%v: got Err %v, want %v", ts.name, tPagerPtr.Err().Error, tErrorInfo.Error)
+			}
+			if tPagerPtr.Next(context.Background()) {
+				t.Errorf("%v: got Next true after the pager stopped, want false", ts.name)
+			}
+		})
+	}
+}
+
+// mockListTeams - answers a ListTeams request from seven teams, T0 to T6. The page token is the offset of the page and the
+// server default page size is three. When stuck is true, every reply has the same next page token and the first page.
+func mockListTeams(callPtr *Call, size string, token string, stuck bool) (errorInfo pi.ErrorInfo) {
+
+	var (
+		tOffset   int
+		tPageSize = 3
+		tReply    ncs.ListTeamsReply
+	)
+
+	if size != "" {
+		tPageSize, _ = strconv.Atoi(size)
+	}
+	if token != "" && stuck == false {
+		tOffset, _ = strconv.Atoi(token)
+	}
+
+	for i := tOffset; i < 7 && i < tOffset+tPageSize; i++ {
+		tReply.Response.Items = append(tReply.Response.Items, ncs.Item{Id: fmt.Sprintf("T%d", i)})
+	}
+	switch {
+	case stuck:
+		callPtr.NextPageToken = "STUCK"
+	case tOffset+tPageSize < 7:
+		callPtr.NextPageToken = strconv.Itoa(tOffset + tPageSize)
+	}
+
+	if callPtr.ReplyData, errorInfo.Error = json.Marshal(tReply); errorInfo.Error != nil {
+		return pi.NewErrorInfo(errorInfo.Error, callPtr.Subject)
+	}
+	if errorInfo.Error = json.Unmarshal(callPtr.ReplyData, callPtr.ReplyPtr); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, callPtr.Subject)
+	}
+
+	return
+}
@@ -0,0 +1,429 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+
+	ctv "github.com/sty-holdings/constant-type-vars-go/v2024"
+	ncs "github.com/sty-holdings/nats-connect-shared/v2024"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+//goland:noinspection ALL
+const (
+	RATE_LIMIT_FAIL_FAST RateLimitPolicy = "fail-fast"
+	RATE_LIMIT_WAIT      RateLimitPolicy = "wait"
+	//
+	RATE_LIMIT_SWEEP_THRESHOLD = 1024
+	SAAS_KEY_FIELD_NAME        = "SaaSKey"
+)
+
+// RateLimitPolicy - what happens to a request when the rate limit is reached. Wait holds the request until a token is
+// available or the context is done. Fail-fast returns a RateLimitError without sending the request.
+type RateLimitPolicy string
+
+// RateLimit - a token bucket. Requests are allowed at RequestsPerSecond, with up to Burst requests at once.
+type RateLimit struct {
+	Burst             int     // Requests allowed at once. The default is RequestsPerSecond rounded up, and at least one.
+	RequestsPerSecond float64 // Zero or less means no limit.
+}
+
+// RateLimitSettings - controls the client rate limiter. A request takes a token from its SaaS key's bucket and from its
+// subject's bucket before each attempt.
+type RateLimitSettings struct {
+	PerSubject     map[string]RateLimit                                                         // Limit by subject, shared by all SaaS keys.
+	PerToken       map[string]RateLimit                                                         // Limit by SaaS key. It replaces TokenDefault for the key.
+	Policy         RateLimitPolicy                                                              // The default is RATE_LIMIT_WAIT.
+	TeamLimitsFunc func(reply ncs.GetTeamLimitsReply) (limit RateLimit, errorInfo pi.ErrorInfo) // Reads the limit in CalibrateRateLimit. Required to calibrate.
+	TokenDefault   RateLimit                                                                    // Limit for each SaaS key not in PerToken.
+}
+
+// RateLimitError - is returned, without sending the request, when the rate limit is reached and the policy is fail-fast.
+// It matches ErrClientRateLimited using errors.Is. ErrRateLimited is only for replies rate limited by the upstream service.
+type RateLimitError struct {
+	RetryAfter time.Duration
+	Subject    string
+}
+
+type rateLimiter struct {
+	mutex          sync.Mutex
+	now            func() time.Time
+	policy         RateLimitPolicy
+	subjectBuckets map[string]*tokenBucket
+	sweepAt        int
+	teamLimitsFunc func(reply ncs.GetTeamLimitsReply) (limit RateLimit, errorInfo pi.ErrorInfo)
+	tokenBuckets   map[string]*tokenBucket
+	tokenDefault   RateLimit
+	tokenLimits    map[string]RateLimit
+}
+
+type tokenBucket struct {
+	limit     RateLimit
+	tokens    float64
+	updatedAt time.Time
+}
+
+// Error - returns the message with the subject and how long until a token is available.
+func (errorPtr *RateLimitError) Error() string {
+
+	return fmt.Sprintf("%v %v%v%v%v", CLIENT_RATE_LIMITED, ctv.TXT_SUBJECT, errorPtr.Subject, TXT_RETRY_AFTER, errorPtr.RetryAfter.Round(time.Millisecond))
+}
+
+// Unwrap - allows errors.Is to match ErrClientRateLimited.
+func (errorPtr *RateLimitError) Unwrap() error {
+
+	return ErrClientRateLimited
+}
+
+// CalibrateRateLimit - gets the team limits with SynaidaGetTeamLimits and sets the rate limit for the request's SaaS key.
+// The limit is read using RateLimitSettings.TeamLimitsFunc, because the shared team limits reply doesn't define a request
+// rate. The rate limiter must be turned on with WithRateLimit.
+//
+//	Customer Messages: None
+//	Errors: ErrRateLimiterOff, ErrTeamLimitsFuncMissing, returned from SynaidaGetTeamLimitsCtx, RateLimitSettings.TeamLimitsFunc
+//	Verifications: None
+func (clientPtr *NCClient) CalibrateRateLimit(ctx context.Context, request ncs.GetTeamLimitsRequest, callOptions ...CallOption) (limit RateLimit, errorInfo pi.ErrorInfo) {
+
+	var (
+		tReply ncs.GetTeamLimitsReply
+	)
+
+	if clientPtr.rateLimiterPtr == nil {
+		errorInfo = pi.NewErrorInfo(ErrRateLimiterOff, fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, ctv.SUB_SYNADIA_GET_TEAM_LIMITS))
+		return
+	}
+	if clientPtr.rateLimiterPtr.teamLimitsFunc == nil {
+		errorInfo = pi.NewErrorInfo(ErrTeamLimitsFuncMissing, fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, ctv.SUB_SYNADIA_GET_TEAM_LIMITS))
+		return
+	}
+
+	if tReply, errorInfo = clientPtr.SynaidaGetTeamLimitsCtx(ctx, request, callOptions...); errorInfo.Error != nil {
+		return
+	}
+
+	if limit, errorInfo = clientPtr.rateLimiterPtr.teamLimitsFunc(tReply); errorInfo.Error != nil {
+		return
+	}
+	clientPtr.rateLimiterPtr.setTokenLimit(request.SaaSKey, limit)
+
+	return
+}
+
+// getSaaSKey - returns the SaaSKey field of the request, or an empty string when it doesn't have one.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func getSaaSKey(request interface{}) string {
+
+	var (
+		tField reflect.Value
+		tValue = reflect.Indirect(reflect.ValueOf(request))
+	)
+
+	if tValue.Kind() != reflect.Struct {
+		return ctv.VAL_EMPTY
+	}
+	if tField = tValue.FieldByName(SAAS_KEY_FIELD_NAME); tField.IsValid() == false || tField.Kind() != reflect.String {
+		return ctv.VAL_EMPTY
+	}
+
+	return tField.String()
+}
+
+// getSaaSKeyDigest - returns a SHA-256 digest of the SaaS key, so the key itself isn't kept by the rate limiter.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func getSaaSKeyDigest(saasKey string) string {
+
+	var (
+		tDigest = sha256.Sum256([]byte(saasKey))
+	)
+
+	return hex.EncodeToString(tDigest[:])
+}
+
+// newRateLimiter - creates the rate limiter. Nil is returned when settingsPtr is nil, which turns the rate limiter off.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func newRateLimiter(settingsPtr *RateLimitSettings) (limiterPtr *rateLimiter) {
+
+	var (
+		settings RateLimitSettings
+	)
+
+	if settingsPtr == nil {
+		return
+	}
+	settings = *settingsPtr
+
+	limiterPtr = &rateLimiter{
+		now:            time.Now,
+		policy:         settings.Policy,
+		subjectBuckets: make(map[string]*tokenBucket),
+		sweepAt:        RATE_LIMIT_SWEEP_THRESHOLD,
+		teamLimitsFunc: settings.TeamLimitsFunc,
+		tokenBuckets:   make(map[string]*tokenBucket),
+		tokenDefault:   settings.TokenDefault,
+		tokenLimits:    make(map[string]RateLimit),
+	}
+	if limiterPtr.policy == ctv.VAL_EMPTY {
+		limiterPtr.policy = RATE_LIMIT_WAIT
+	}
+	for subject, limit := range settings.PerSubject {
+		if limit.RequestsPerSecond > 0 {
+			limiterPtr.subjectBuckets[subject] = newTokenBucket(limit, limiterPtr.now())
+		}
+	}
+	for saasKey, limit := range settings.PerToken {
+		limiterPtr.tokenLimits[getSaaSKeyDigest(saasKey)] = limit
+	}
+
+	return
+}
+
+// newTokenBucket - returns a full bucket for the limit, last updated at now.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func newTokenBucket(limit RateLimit, now time.Time) (bucketPtr *tokenBucket) {
+
+	if limit.Burst <= 0 {
+		limit.Burst = max(1, int(math.Ceil(limit.RequestsPerSecond)))
+	}
+
+	return &tokenBucket{
+		limit:     limit,
+		tokens:    float64(limit.Burst),
+		updatedAt: now,
+	}
+}
+
+// intercept - takes a token from the SaaS key and subject buckets before the attempt. With the wait policy, the attempt
+// waits until both have a token. Safe to call on a nil rate limiter.
+//
+//	Customer Messages: None
+//	Errors: RateLimitError, ctx.Err, returned from next
+//	Verifications: None
+func (limiterPtr *rateLimiter) intercept(ctx context.Context, callPtr *Call, next Invoker) (errorInfo pi.ErrorInfo) {
+
+	var (
+		ok        bool
+		tSaaSKey  string
+		tTimerPtr *time.Timer
+		tWait     time.Duration
+	)
+
+	if limiterPtr == nil {
+		return next(ctx, callPtr)
+	}
+
+	tSaaSKey = getSaaSKey(callPtr.Request)
+	if tWait, ok = limiterPtr.reserve(callPtr.Subject, tSaaSKey); ok == false {
+		errorInfo = pi.NewErrorInfo(&RateLimitError{RetryAfter: tWait, Subject: callPtr.Subject}, fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, callPtr.Subject))
+		return
+	}
+
+	if tWait > 0 {
+		tTimerPtr = time.NewTimer(tWait)
+		defer tTimerPtr.Stop()
+		select {
+		case <-tTimerPtr.C:
+		case <-ctx.Done():
+			limiterPtr.cancel(callPtr.Subject, tSaaSKey)
+			errorInfo = pi.NewErrorInfo(ctx.Err(), fmt.Sprintf("%v%v", ctv.TXT_SUBJECT, callPtr.Subject))
+			return
+		}
+	}
+
+	return next(ctx, callPtr)
+}
+
+// cancel - returns the tokens taken by reserve when the request stops waiting. A bucket that was removed, or replaced by
+// setTokenLimit, is not created again and gets nothing back.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (limiterPtr *rateLimiter) cancel(subject, saasKey string) {
+
+	var (
+		ok         bool
+		tBucketPtr *tokenBucket
+	)
+
+	limiterPtr.mutex.Lock()
+	defer limiterPtr.mutex.Unlock()
+
+	if tBucketPtr, ok = limiterPtr.subjectBuckets[subject]; ok {
+		tBucketPtr.tokens = min(tBucketPtr.tokens+1, float64(tBucketPtr.limit.Burst))
+	}
+	if tBucketPtr, ok = limiterPtr.tokenBuckets[getSaaSKeyDigest(saasKey)]; ok {
+		tBucketPtr.tokens = min(tBucketPtr.tokens+1, float64(tBucketPtr.limit.Burst))
+	}
+}
+
+// getBuckets - returns the buckets that apply to the request, creating the SaaS key bucket if needed. Idle SaaS key buckets
+// are removed before the map grows past sweepAt. The caller must hold the mutex.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (limiterPtr *rateLimiter) getBuckets(subject, saasKey string) (buckets []*tokenBucket) {
+
+	var (
+		ok          bool
+		tBucketPtr  *tokenBucket
+		tDigest     = getSaaSKeyDigest(saasKey)
+		tTokenLimit RateLimit
+	)
+
+	if tBucketPtr, ok = limiterPtr.subjectBuckets[subject]; ok {
+		buckets = append(buckets, tBucketPtr)
+	}
+
+	if tBucketPtr, ok = limiterPtr.tokenBuckets[tDigest]; ok == false {
+		if tTokenLimit, ok = limiterPtr.tokenLimits[tDigest]; ok == false {
+			tTokenLimit = limiterPtr.tokenDefault
+		}
+		if tTokenLimit.RequestsPerSecond <= 0 {
+			return
+		}
+		if len(limiterPtr.tokenBuckets) >= limiterPtr.sweepAt {
+			limiterPtr.removeIdleBuckets(limiterPtr.now())
+		}
+		tBucketPtr = newTokenBucket(tTokenLimit, limiterPtr.now())
+		limiterPtr.tokenBuckets[tDigest] = tBucketPtr
+	}
+
+	return append(buckets, tBucketPtr)
+}
+
+// removeIdleBuckets - removes the SaaS key buckets that have refilled to Burst. A full bucket is the same as a new one, so
+// no limit is lost. sweepAt is raised when most buckets are still in use, so the sweep doesn't run for every new key. The
+// caller must hold the mutex.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (limiterPtr *rateLimiter) removeIdleBuckets(now time.Time) {
+
+	for digest, bucketPtr := range limiterPtr.tokenBuckets {
+		if bucketPtr.refill(now); bucketPtr.tokens >= float64(bucketPtr.limit.Burst) {
+			delete(limiterPtr.tokenBuckets, digest)
+		}
+	}
+	limiterPtr.sweepAt = max(RATE_LIMIT_SWEEP_THRESHOLD, 2*len(limiterPtr.tokenBuckets))
+}
+
+// reserve - takes a token from each bucket for the request and returns how long to wait until the tokens are available.
+// With the fail-fast policy, no tokens are taken and false is returned when a bucket is empty.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (limiterPtr *rateLimiter) reserve(subject, saasKey string) (wait time.Duration, ok bool) {
+
+	var (
+		tBuckets []*tokenBucket
+		tNow     time.Time
+	)
+
+	limiterPtr.mutex.Lock()
+	defer limiterPtr.mutex.Unlock()
+
+	tNow = limiterPtr.now()
+	tBuckets = limiterPtr.getBuckets(subject, saasKey)
+	for _, bucketPtr := range tBuckets {
+		bucketPtr.refill(tNow)
+		if bucketPtr.tokens < 1 {
+			wait = max(wait, bucketPtr.getWait())
+		}
+	}
+	if wait > 0 && limiterPtr.policy == RATE_LIMIT_FAIL_FAST {
+		return
+	}
+
+	for _, bucketPtr := range tBuckets {
+		bucketPtr.tokens--
+	}
+
+	return wait, true
+}
+
+// setTokenLimit - sets the limit for the SaaS key, replacing its bucket.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (limiterPtr *rateLimiter) setTokenLimit(saasKey string, limit RateLimit) {
+
+	var (
+		tDigest = getSaaSKeyDigest(saasKey)
+	)
+
+	limiterPtr.mutex.Lock()
+	defer limiterPtr.mutex.Unlock()
+
+	limiterPtr.tokenLimits[tDigest] = limit
+	delete(limiterPtr.tokenBuckets, tDigest)
+}
+
+// getWait - returns how long until the bucket has a whole token.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (bucketPtr *tokenBucket) getWait() time.Duration {
+
+	return time.Duration((1 - bucketPtr.tokens) / bucketPtr.limit.RequestsPerSecond * float64(time.Second))
+}
+
+// refill - adds the tokens earned since the last update, up to Burst. A time before the last update, such as one read before
+// the bucket was created, adds nothing.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (bucketPtr *tokenBucket) refill(now time.Time) {
+
+	if now.Before(bucketPtr.updatedAt) {
+		return
+	}
+	bucketPtr.tokens = min(bucketPtr.tokens+now.Sub(bucketPtr.updatedAt).Seconds()*bucketPtr.limit.RequestsPerSecond, float64(bucketPtr.limit.Burst))
+	bucketPtr.updatedAt = now
+}
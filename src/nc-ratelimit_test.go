@@ -0,0 +1,236 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	ncs "github.com/sty-holdings/nats-connect-shared/v2024"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+func TestRateLimiterReserve(tPtr *testing.T) {
+
+	var (
+		tests = []struct {
+			name      string
+			settings  RateLimitSettings
+			requests  int    // Requests reserved without a wait.
+			nextKey   string // The SaaS key of the next request.
+			wantWait  bool   // The next request has to wait.
+			wantError bool   // The next request is refused.
+		}{
+			{
+				name:     "Positive Case: The burst is allowed at once.",
+				settings: RateLimitSettings{TokenDefault: RateLimit{Burst: 3, RequestsPerSecond: 1}},
+				requests: 3,
+			},
+			{
+				name:     "Positive Case: The wait policy waits when the bucket is empty.",
+				settings: RateLimitSettings{TokenDefault: RateLimit{Burst: 2, RequestsPerSecond: 1}},
+				requests: 2,
+				nextKey:  "KEY",
+				wantWait: true,
+			},
+			{
+				name:      "Negative Case: The fail-fast policy refuses when the bucket is empty.",
+				settings:  RateLimitSettings{Policy: RATE_LIMIT_FAIL_FAST, TokenDefault: RateLimit{RequestsPerSecond: 1}},
+				requests:  1,
+				nextKey:   "KEY",
+				wantError: true,
+			},
+			{
+				name: "Negative Case: The subject bucket applies to every SaaS key.",
+				settings: RateLimitSettings{
+					PerSubject: map[string]RateLimit{"TEST_SUBJECT": {Burst: 1, RequestsPerSecond: 1}},
+					Policy:     RATE_LIMIT_FAIL_FAST,
+				},
+				requests:  1,
+				nextKey:   "OTHER_KEY",
+				wantError: true,
+			},
+			{
+				name:     "Positive Case: No limit.",
+				settings: RateLimitSettings{},
+				requests: 100,
+			},
+		}
+	)
+
+	for _, ts := range tests {
+		tPtr.Run(
+			ts.name, func(t *testing.T) {
+				var (
+					ok          bool
+					tLimiterPtr = newRateLimiter(&ts.settings)
+					tWait       time.Duration
+				)
+
+				for i := 0; i < ts.requests; i++ {
+					if tWait, ok = tLimiterPtr.reserve("TEST_SUBJECT", "KEY"); ok == false || tWait > 0 {
+						t.Fatalf("%v: request %d: got wait %v and ok %v", ts.name, i, tWait, ok)
+					}
+				}
+				if ts.wantWait == false && ts.wantError == false {
+					return
+				}
+				if tWait, ok = tLimiterPtr.reserve("TEST_SUBJECT", ts.nextKey); ok == ts.wantError || tWait <= 0 || tWait > time.Second {
+					t.Errorf("%v: got wait %v and ok %v", ts.name, tWait, ok)
+				}
+			},
+		)
+	}
+}
+
+func TestTokenBucketRefill(tPtr *testing.T) {
+
+	var (
+		tNow       = time.Now()
+		tBucketPtr = newTokenBucket(RateLimit{RequestsPerSecond: 10}, tNow)
+	)
+
+	if tBucketPtr.limit.Burst != 10 || tBucketPtr.tokens != 10 {
+		tPtr.Fatalf("got burst %d and %v tokens, want a full bucket of 10", tBucketPtr.limit.Burst, tBucketPtr.tokens)
+	}
+	tBucketPtr.tokens = 0
+	if tWait := tBucketPtr.getWait(); tWait != 100*time.Millisecond {
+		tPtr.Errorf("got wait %v, want 100ms", tWait)
+	}
+	tBucketPtr.refill(tNow.Add(250 * time.Millisecond))
+	if tBucketPtr.tokens < 2.49 || tBucketPtr.tokens > 2.51 {
+		tPtr.Errorf("got %v tokens, want 2.5", tBucketPtr.tokens)
+	}
+	tBucketPtr.refill(tNow.Add(time.Hour))
+	if tBucketPtr.tokens != 10 {
+		tPtr.Errorf("got %v tokens, want the burst of 10", tBucketPtr.tokens)
+	}
+}
+
+func TestRateLimiterIntercept(tPtr *testing.T) {
+
+	var (
+		tCallPtr    = &Call{Request: ncs.GetTeamRequest{SaaSKey: "KEY"}, Subject: "TEST_SUBJECT"}
+		tErrorInfo  pi.ErrorInfo
+		tLimiterPtr = newRateLimiter(&RateLimitSettings{Policy: RATE_LIMIT_FAIL_FAST, TokenDefault: RateLimit{RequestsPerSecond: 1}})
+		tNext       = func(ctx context.Context, callPtr *Call) pi.ErrorInfo { return pi.ErrorInfo{} }
+		tRateErrPtr *RateLimitError
+	)
+
+	if tErrorInfo = tLimiterPtr.intercept(context.Background(), tCallPtr, tNext); tErrorInfo.Error != nil {
+		tPtr.Fatalf("got error %v, want the first request sent", tErrorInfo.Error)
+	}
+	tErrorInfo = tLimiterPtr.intercept(context.Background(), tCallPtr, tNext)
+	if errors.Is(tErrorInfo.Error, ErrClientRateLimited) == false || errors.Is(tErrorInfo.Error, ErrRateLimited) {
+		tPtr.Fatalf("got error %v, want only ErrClientRateLimited", tErrorInfo.Error)
+	}
+	if errors.As(tErrorInfo.Error, &tRateErrPtr) == false || tRateErrPtr.RetryAfter <= 0 {
+		tPtr.Errorf("got error %v, want a RateLimitError with RetryAfter", tErrorInfo.Error)
+	}
+	if tClass := getErrorClass(tErrorInfo.Error); tClass != ERROR_CLASS_CLIENT_RATE_LIMITED {
+		tPtr.Errorf("got error class %v, want %v", tClass, ERROR_CLASS_CLIENT_RATE_LIMITED)
+	}
+}
+
+func TestRemoveIdleBuckets(tPtr *testing.T) {
+
+	var (
+		tLimiterPtr = newRateLimiter(&RateLimitSettings{TokenDefault: RateLimit{Burst: 1, RequestsPerSecond: 1000}})
+		tNow        = time.Now()
+	)
+
+	tLimiterPtr.now = func() time.Time { return tNow }
+
+	for i := 0; i < RATE_LIMIT_SWEEP_THRESHOLD; i++ {
+		tLimiterPtr.reserve("TEST_SUBJECT", fmt.Sprintf("KEY_%d", i))
+	}
+	if len(tLimiterPtr.tokenBuckets) != RATE_LIMIT_SWEEP_THRESHOLD {
+		tPtr.Fatalf("got %d buckets, want %d", len(tLimiterPtr.tokenBuckets), RATE_LIMIT_SWEEP_THRESHOLD)
+	}
+
+	tNow = tNow.Add(5 * time.Millisecond) // The buckets refill to Burst.
+	tLimiterPtr.reserve("TEST_SUBJECT", "NEW_KEY")
+	if len(tLimiterPtr.tokenBuckets) != 1 {
+		tPtr.Errorf("got %d buckets after the sweep, want only the new key", len(tLimiterPtr.tokenBuckets))
+	}
+	if tLimiterPtr.sweepAt != RATE_LIMIT_SWEEP_THRESHOLD {
+		tPtr.Errorf("got sweepAt %d, want %d", tLimiterPtr.sweepAt, RATE_LIMIT_SWEEP_THRESHOLD)
+	}
+}
+
+func TestRateLimiterCancel(tPtr *testing.T) {
+
+	var (
+		tDigest     = getSaaSKeyDigest("KEY")
+		tLimiterPtr = newRateLimiter(&RateLimitSettings{TokenDefault: RateLimit{Burst: 2, RequestsPerSecond: 1}})
+	)
+
+	tLimiterPtr.cancel("TEST_SUBJECT", "KEY")
+	if len(tLimiterPtr.tokenBuckets) != 0 {
+		tPtr.Fatalf("got %d buckets, want cancel to not create a bucket", len(tLimiterPtr.tokenBuckets))
+	}
+
+	tLimiterPtr.reserve("TEST_SUBJECT", "KEY")
+	tLimiterPtr.cancel("TEST_SUBJECT", "KEY")
+	if tTokens := tLimiterPtr.tokenBuckets[tDigest].tokens; tTokens != 2 {
+		tPtr.Errorf("got %v tokens, want the token returned", tTokens)
+	}
+
+	tLimiterPtr.reserve("TEST_SUBJECT", "KEY")
+	tLimiterPtr.setTokenLimit("KEY", RateLimit{Burst: 5, RequestsPerSecond: 5})
+	tLimiterPtr.cancel("TEST_SUBJECT", "KEY")
+	if len(tLimiterPtr.tokenBuckets) != 0 {
+		tPtr.Errorf("got %d buckets, want the replaced bucket not created again", len(tLimiterPtr.tokenBuckets))
+	}
+}
+
+func TestCalibrateRateLimit(tPtr *testing.T) {
+
+	var (
+		tClientPtr = newMockClient(func(ctx context.Context, callPtr *Call, next Invoker) pi.ErrorInfo { return pi.ErrorInfo{} })
+		tErrorInfo pi.ErrorInfo
+	)
+
+	if _, tErrorInfo = tClientPtr.CalibrateRateLimit(context.Background(), ncs.GetTeamLimitsRequest{}); errors.Is(tErrorInfo.Error, ErrRateLimiterOff) == false {
+		tPtr.Errorf("got error %v, want %v", tErrorInfo.Error, ErrRateLimiterOff)
+	}
+
+	tClientPtr.rateLimiterPtr = newRateLimiter(&RateLimitSettings{})
+	if _, tErrorInfo = tClientPtr.CalibrateRateLimit(context.Background(), ncs.GetTeamLimitsRequest{}); errors.Is(tErrorInfo.Error, ErrTeamLimitsFuncMissing) == false {
+		tPtr.Errorf("got error %v, want %v", tErrorInfo.Error, ErrTeamLimitsFuncMissing)
+	}
+
+	tClientPtr.rateLimiterPtr.teamLimitsFunc = func(reply ncs.GetTeamLimitsReply) (limit RateLimit, errorInfo pi.ErrorInfo) {
+		return RateLimit{RequestsPerSecond: 2}, pi.ErrorInfo{}
+	}
+	if _, tErrorInfo = tClientPtr.CalibrateRateLimit(context.Background(), ncs.GetTeamLimitsRequest{SaaSKey: "KEY"}); tErrorInfo.Error != nil {
+		tPtr.Fatalf("got error %v, want none", tErrorInfo.Error)
+	}
+	if tLimit := tClientPtr.rateLimiterPtr.tokenLimits[getSaaSKeyDigest("KEY")]; tLimit.RequestsPerSecond != 2 {
+		tPtr.Errorf("got limit %+v, want 2 requests per second", tLimit)
+	}
+}
@@ -0,0 +1,103 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	ctv "github.com/sty-holdings/constant-type-vars-go/v2024"
+	ncs "github.com/sty-holdings/nats-connect-shared/v2024"
+)
+
+// Endpoint - a NATS Connect request type paired with its reply type and subject. Do, DoAsync, DoBatch and NewPager take an
+// endpoint, so the compiler checks the request and reply types, for example, Do(ctx, clientPtr, SynaidaGetTeamEndpoint, request).
+// Use the endpoints declared below. The zero Endpoint isn't registered and its requests fail with ErrEndpointNotRegistered.
+type Endpoint[Request any, Reply any] struct {
+	endpoint
+}
+
+// endpoint - describes how a request is sent: the subject and whether the request is read-only. Read-only requests don't
+// change anything, so they are retried by default.
+type endpoint struct {
+	readOnly bool
+	subject  string
+}
+
+// The NATS Connect endpoints. Adding an endpoint only needs an entry here and a method on NCClient.
+var (
+	SynaidaGetPersonalAccessTokenEndpoint = Endpoint[ncs.GetPersonalAccessTokenRequest, ncs.GetPersonalAccessTokenReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_GET_PERSONAL_ACCESS_TOKEN},
+	}
+	SynaidaGetSystemEndpoint = Endpoint[ncs.GetSystemRequest, ncs.GetSystemReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_GET_SYSTEM},
+	}
+	SynaidaGetSystemLimitsEndpoint = Endpoint[ncs.GetSystemLimitsRequest, ncs.GetSystemLimitsReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_GET_SYSTEM_LIMITS},
+	}
+	SynaidaGetTeamEndpoint = Endpoint[ncs.GetTeamRequest, ncs.GetTeamReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_GET_TEAM},
+	}
+	SynaidaGetTeamLimitsEndpoint = Endpoint[ncs.GetTeamLimitsRequest, ncs.GetTeamLimitsReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_GET_TEAM_LIMITS},
+	}
+	SynaidaGetVersionEndpoint = Endpoint[ncs.GetVersionRequest, ncs.GetVersionReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_GET_VERSION},
+	}
+	SynaidaListAccountsEndpoint = Endpoint[ncs.ListAccountsRequest, ncs.ListAccountsReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_LIST_ACCOUNT},
+	}
+	SynaidaListInfoAppUsersTeamEndpoint = Endpoint[ncs.ListInfoAppUserTeamRequest, ncs.ListInfoAppUsersTeamReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_LIST_INFO_APP_USERS_TEAM},
+	}
+	SynaidaListNATSUsersEndpoint = Endpoint[ncs.ListNATSUsersRequest, ncs.ListNATSUsersReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_LIST_NATS_USERS},
+	}
+	SynaidaListPersonalAccessTokensEndpoint = Endpoint[ncs.ListPersonalAccessTokensRequest, ncs.ListPersonalAccessTokensReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_LIST_PERSONAL_ACCESS_TOKENS},
+	}
+	SynaidaListSystemAccountInfoEndpoint = Endpoint[ncs.ListSystemAccountInfoRequest, ncs.ListSystemAccountInfoReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_LIST_SYSTEM_ACCOUN_TINFO},
+	}
+	SynaidaListSystemServerInfoEndpoint = Endpoint[ncs.ListSystemServerInfoRequest, ncs.ListSystemServerInfoReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_LIST_SYSTEM_SERVER_INFO},
+	}
+	SynaidaListSystemsEndpoint = Endpoint[ncs.ListSystemsRequest, ncs.ListSystemsReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_LIST_SYSTEMS},
+	}
+	SynaidaListTeamServerAccountsEndpoint = Endpoint[ncs.ListTeamServerAccountsRequest, ncs.ListTeamServerAccountsReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_LIST_TEAM_SERVER_ACCOUNTS},
+	}
+	SynaidaListTeamsEndpoint = Endpoint[ncs.ListTeamsRequest, ncs.ListTeamsReply]{
+		endpoint{readOnly: true, subject: ctv.SUB_SYNADIA_LIST_TEAMS},
+	}
+)
+
+// Subject - returns the subject the endpoint's requests are sent on, for example, to use with InvalidateCache or
+// CircuitBreakerState.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (requestEndpoint Endpoint[Request, Reply]) Subject() string {
+
+	return requestEndpoint.subject
+}
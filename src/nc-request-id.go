@@ -0,0 +1,92 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"runtime/debug"
+
+	"github.com/nats-io/nuid"
+
+	ctv "github.com/sty-holdings/constant-type-vars-go/v2024"
+)
+
+//goland:noinspection ALL
+const (
+	CLIENT_MODULE_PATH    = "github.com/sty-holdings/nats-connect-go-client"
+	CLIENT_VERSION_DEVEL  = "(devel)"
+	HEADER_CLIENT_VERSION = "NC-Client-Version" // Request header. The version of this client library.
+	HEADER_REQUEST_ID     = "NC-Request-Id"     // Request header. Identifies the request in the client and server logs.
+)
+
+// ReplyInfo - information about a request that isn't part of the reply. Use WithCallReplyInfo to have it filled in.
+type ReplyInfo struct {
+	Cached        bool   // True when the reply came from the reply cache and no request was sent.
+	NextPageToken string // The NC-Next-Page-Token reply header. Pass it to WithCallPage for the next page. Empty on the last page.
+	RequestId     string // The request id sent in the NC-Request-Id header. It is the same for every attempt.
+}
+
+// clientVersion - the client library version sent with every request.
+var clientVersion = getClientVersion()
+
+// getClientVersion - returns the version of this module from the build information. When the client is built from its
+// own repository, the version is CLIENT_VERSION_DEVEL.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func getClientVersion() (version string) {
+
+	var (
+		ok            bool
+		tBuildInfoPtr *debug.BuildInfo
+	)
+
+	if tBuildInfoPtr, ok = debug.ReadBuildInfo(); ok == false {
+		return CLIENT_VERSION_DEVEL
+	}
+	if tBuildInfoPtr.Main.Path == CLIENT_MODULE_PATH {
+		return tBuildInfoPtr.Main.Version
+	}
+	for _, modulePtr := range tBuildInfoPtr.Deps {
+		if modulePtr.Path == CLIENT_MODULE_PATH {
+			return modulePtr.Version
+		}
+	}
+
+	return CLIENT_VERSION_DEVEL
+}
+
+// newRequestId - returns the caller supplied request id, or a new unique id when there isn't one.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func newRequestId(requestId string) string {
+
+	if requestId != ctv.VAL_EMPTY {
+		return requestId
+	}
+
+	return nuid.Next()
+}
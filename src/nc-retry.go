@@ -0,0 +1,193 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+//goland:noinspection ALL
+const (
+	DEFAULT_RETRY_BACKOFF      = 100 * time.Millisecond
+	DEFAULT_RETRY_JITTER       = 0.2
+	DEFAULT_RETRY_MAX_ATTEMPTS = 3
+	DEFAULT_RETRY_MAX_BACKOFF  = 2 * time.Second
+)
+
+// RetryPolicy - controls how failed requests are retried. Each retry is marshalled and encrypted again before it is sent.
+// ErrRateLimited in RetryableErrors retries replies rate limited by the upstream service. ErrClientRateLimited retries
+// requests refused by the client rate limiter, waiting at least the RateLimitError RetryAfter.
+type RetryPolicy struct {
+	Backoff         time.Duration // Wait before the first retry. The wait doubles for each following retry.
+	Jitter          float64       // Fraction of the wait that is randomized, from 0 to 1.
+	MaxAttempts     int           // Total attempts including the first. One or less turns off retries.
+	MaxBackoff      time.Duration // Longest wait between attempts. Zero means no limit.
+	RetryableErrors []error       // A failed attempt is retried when its error matches one of these using errors.Is.
+}
+
+// DefaultRetryPolicy - returns the policy used for read-only requests: three attempts, starting with a 100ms backoff, when
+// there are no responders or the request timed out.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func DefaultRetryPolicy() RetryPolicy {
+
+	return RetryPolicy{
+		Backoff:         DEFAULT_RETRY_BACKOFF,
+		Jitter:          DEFAULT_RETRY_JITTER,
+		MaxAttempts:     DEFAULT_RETRY_MAX_ATTEMPTS,
+		MaxBackoff:      DEFAULT_RETRY_MAX_BACKOFF,
+		RetryableErrors: []error{nats.ErrNoResponders, nats.ErrTimeout, ErrRequestTimeout},
+	}
+}
+
+// NoRetryPolicy - returns a policy that makes a single attempt.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func NoRetryPolicy() RetryPolicy {
+
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// RetryInterceptor - repeats the rest of the chain when an attempt fails with a retryable error. The policy applies to
+// read-only requests. WithCallRetryPolicy replaces it for a single call, including requests that change data.
+//
+//	Customer Messages: None
+//	Errors: returned from next
+//	Verifications: None
+func RetryInterceptor(policy RetryPolicy) Interceptor {
+
+	return func(ctx context.Context, callPtr *Call, next Invoker) (errorInfo pi.ErrorInfo) {
+
+		var (
+			tPolicy = NoRetryPolicy()
+		)
+
+		if callPtr.options.retryPolicyPtr != nil {
+			tPolicy = *callPtr.options.retryPolicyPtr
+		} else if callPtr.ReadOnly {
+			tPolicy = policy
+		}
+
+		for tAttempt := 1; ; tAttempt++ {
+			errorInfo = next(ctx, callPtr)
+			if errorInfo.Error == nil || tAttempt >= tPolicy.MaxAttempts || tPolicy.isRetryable(errorInfo.Error) == false || tPolicy.wait(ctx, tAttempt, getRetryAfter(errorInfo.Error)) == false {
+				return
+			}
+		}
+	}
+}
+
+// getRetryAfter - returns how long the client rate limiter asked to wait, or zero for any other error.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func getRetryAfter(err error) (retryAfter time.Duration) {
+
+	var (
+		tRateLimitErrorPtr *RateLimitError
+	)
+
+	if errors.As(err, &tRateLimitErrorPtr) {
+		retryAfter = tRateLimitErrorPtr.RetryAfter
+	}
+
+	return
+}
+
+// isRetryable - returns true when the error matches one of the retryable errors.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (policy RetryPolicy) isRetryable(err error) bool {
+
+	for _, retryableError := range policy.RetryableErrors {
+		if errors.Is(err, retryableError) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff - returns the wait before the retry. The wait is Backoff doubled for each earlier retry, limited to MaxBackoff, and
+// adjusted up or down by Jitter. It is at least minimum.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (policy RetryPolicy) backoff(retry int, minimum time.Duration) (backoff time.Duration) {
+
+	backoff = policy.Backoff
+	for i := 1; i < retry && (policy.MaxBackoff <= 0 || backoff < policy.MaxBackoff); i++ {
+		backoff *= 2
+	}
+	if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+		backoff = policy.MaxBackoff
+	}
+	if policy.Jitter > 0 {
+		backoff += time.Duration(float64(backoff) * policy.Jitter * (2*rand.Float64() - 1))
+	}
+
+	return max(backoff, minimum)
+}
+
+// wait - sleeps for the backoff before the next attempt. False is returned if the context is done first.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (policy RetryPolicy) wait(ctx context.Context, retry int, minimum time.Duration) bool {
+
+	var (
+		tTimerPtr *time.Timer
+		tWait     = policy.backoff(retry, minimum)
+	)
+
+	if tWait <= 0 {
+		return ctx.Err() == nil
+	}
+
+	tTimerPtr = time.NewTimer(tWait)
+	defer tTimerPtr.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-tTimerPtr.C:
+		return true
+	}
+}
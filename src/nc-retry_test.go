@@ -0,0 +1,186 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+func TestRetryPolicyBackoff(tPtr *testing.T) {
+
+	var (
+		tests = []struct {
+			name    string
+			policy  RetryPolicy
+			retry   int
+			minimum time.Duration
+			want    time.Duration
+		}{
+			{
+				name:   "Positive Case: First retry waits Backoff.",
+				policy: RetryPolicy{Backoff: 20 * time.Millisecond},
+				retry:  1,
+				want:   20 * time.Millisecond,
+			},
+			{
+				name:   "Positive Case: The wait doubles for each retry.",
+				policy: RetryPolicy{Backoff: 10 * time.Millisecond},
+				retry:  3,
+				want:   40 * time.Millisecond,
+			},
+			{
+				name:   "Positive Case: The wait is limited to MaxBackoff.",
+				policy: RetryPolicy{Backoff: 10 * time.Millisecond, MaxBackoff: 30 * time.Millisecond},
+				retry:  10,
+				want:   30 * time.Millisecond,
+			},
+			{
+				name:    "Positive Case: The wait is at least minimum.",
+				policy:  RetryPolicy{Backoff: time.Millisecond},
+				retry:   1,
+				minimum: 50 * time.Millisecond,
+				want:    50 * time.Millisecond,
+			},
+		}
+	)
+
+	for _, ts := range tests {
+		tPtr.Run(
+			ts.name, func(t *testing.T) {
+				if tGot := ts.policy.backoff(ts.retry, ts.minimum); tGot != ts.want {
+					t.Errorf("%v: got %v, want %v", ts.name, tGot, ts.want)
+				}
+			},
+		)
+	}
+}
+
+func TestRetryPolicyBackoffJitter(tPtr *testing.T) {
+
+	var (
+		tPolicy = RetryPolicy{Backoff: 40 * time.Millisecond, Jitter: 0.5}
+	)
+
+	for i := 0; i < 100; i++ {
+		if tGot := tPolicy.backoff(1, 0); tGot < 20*time.Millisecond || tGot > 60*time.Millisecond {
+			tPtr.Fatalf("got %v, want between 20ms and 60ms", tGot)
+		}
+	}
+}
+
+func TestRetryPolicyWait(tPtr *testing.T) {
+
+	var (
+		tCancel context.CancelFunc
+		tCtx    context.Context
+	)
+
+	if (RetryPolicy{}).wait(context.Background(), 1, 0) == false {
+		tPtr.Errorf("got false without a wait, want true")
+	}
+
+	tCtx, tCancel = context.WithCancel(context.Background())
+	tCancel()
+	if (RetryPolicy{Backoff: time.Hour}).wait(tCtx, 1, 0) {
+		tPtr.Errorf("got true with a done context, want false")
+	}
+}
+
+func TestRetryInterceptor(tPtr *testing.T) {
+
+	var (
+		tests = []struct {
+			name         string
+			err          error
+			readOnly     bool
+			policyPtr    *RetryPolicy
+			wantAttempts int
+		}{
+			{
+				name:         "Positive Case: Read-only requests are retried.",
+				err:          nats.ErrNoResponders,
+				readOnly:     true,
+				wantAttempts: 3,
+			},
+			{
+				name:         "Positive Case: Requests that change data aren't retried.",
+				err:          nats.ErrNoResponders,
+				wantAttempts: 1,
+			},
+			{
+				name:         "Positive Case: The call policy replaces the client policy.",
+				err:          nats.ErrNoResponders,
+				policyPtr:    &RetryPolicy{MaxAttempts: 2, RetryableErrors: []error{nats.ErrNoResponders}},
+				wantAttempts: 2,
+			},
+			{
+				name:         "Negative Case: Errors that aren't retryable.",
+				err:          ErrNotFound,
+				readOnly:     true,
+				wantAttempts: 1,
+			},
+			{
+				name:         "Negative Case: The client rate limit isn't the upstream rate limit.",
+				err:          &RateLimitError{Subject: "TEST_SUBJECT"},
+				policyPtr:    &RetryPolicy{MaxAttempts: 3, RetryableErrors: []error{ErrRateLimited}},
+				wantAttempts: 1,
+			},
+			{
+				name:         "Positive Case: The client rate limit is retried when listed.",
+				err:          &RateLimitError{Subject: "TEST_SUBJECT"},
+				policyPtr:    &RetryPolicy{MaxAttempts: 3, RetryableErrors: []error{ErrClientRateLimited}},
+				wantAttempts: 3,
+			},
+		}
+	)
+
+	for _, ts := range tests {
+		tPtr.Run(
+			ts.name, func(t *testing.T) {
+				var (
+					tAttempts    int
+					tCall        = Call{ReadOnly: ts.readOnly}
+					tInterceptor = RetryInterceptor(RetryPolicy{MaxAttempts: 3, RetryableErrors: []error{nats.ErrNoResponders}})
+				)
+
+				tCall.options.retryPolicyPtr = ts.policyPtr
+				_ = tInterceptor(
+					context.Background(), &tCall, func(ctx context.Context, callPtr *Call) pi.ErrorInfo {
+						tAttempts++
+						return pi.NewErrorInfo(ts.err, "test")
+					},
+				)
+				if tAttempts != ts.wantAttempts {
+					t.Errorf("%v: got %d attempts, want %d", ts.name, tAttempts, ts.wantAttempts)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,407 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsCIP "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	awsCT "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/golang-jwt/jwt/v5"
+
+	ctv "github.com/sty-holdings/constant-type-vars-go/v2024"
+	awss "github.com/sty-holdings/sty-shared/v2024/awsServices"
+	ns "github.com/sty-holdings/sty-shared/v2024/natsSerices"
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+//goland:noinspection ALL
+const (
+	COGNITO_CLIENT_ID_FIELD  = "clientId" // The app client id field of awss.CognitoIdentityInfo.
+	COGNITO_REFRESH_TOKEN    = "REFRESH_TOKEN"
+	COGNITO_SECRET_HASH      = "SECRET_HASH"
+	COGNITO_USERNAME         = "USERNAME"
+	TOKEN_REFRESH_LEAD_TIME  = 5 * time.Minute  // Tokens are renewed this long before they expire.
+	TOKEN_REFRESH_RETRY_WAIT = 30 * time.Second // Minimum wait between refresh attempts.
+	TOKEN_REFRESH_TIMEOUT    = 30 * time.Second
+)
+
+type tokenRefresher struct {
+	awsSettings   awss.AWSSettings
+	clientId      string
+	clientSecret  string
+	doneChan      chan struct{}
+	environment   string
+	expiresAt     time.Time
+	lastErrorInfo pi.ErrorInfo
+	loggerPtr     *slog.Logger
+	mutex         sync.RWMutex
+	natsConfig    ns.NATSConfiguration
+	started       bool
+	stopChan      chan struct{}
+	stopOnce      sync.Once
+	tempDirectory string
+	tokens        awss.CognitoTokens
+	username      string
+}
+
+// newTokenRefresher - creates the refresher that keeps the Cognito tokens current. The refresher holds the only copy of the
+// tokens and the NATS configuration, read them through it. The tokens are refreshed with the app client that logged in. The
+// refresher isn't running until start is called.
+//
+//	Customer Messages: None
+//	Errors: ErrRequiredArgumentMissing, returned from getTokenExpiry
+//	Verifications: None
+func newTokenRefresher(
+	awsSettings awss.AWSSettings,
+	tokens awss.CognitoTokens,
+	environment, tempDirectory, username, clientSecret string,
+	natsConfig ns.NATSConfiguration,
+	loggerPtr *slog.Logger,
+) (
+	refresherPtr *tokenRefresher,
+	errorInfo pi.ErrorInfo,
+) {
+
+	refresherPtr = &tokenRefresher{
+		awsSettings:   awsSettings,
+		clientId:      getCognitoClientId(awsSettings.STYHCognitoIdentityInfo),
+		clientSecret:  clientSecret,
+		doneChan:      make(chan struct{}),
+		environment:   environment,
+		loggerPtr:     loggerPtr,
+		natsConfig:    natsConfig,
+		stopChan:      make(chan struct{}),
+		tempDirectory: tempDirectory,
+		tokens:        tokens,
+		username:      username,
+	}
+
+	if refresherPtr.clientId == ctv.VAL_EMPTY {
+		errorInfo = pi.NewErrorInfo(pi.ErrRequiredArgumentMissing, fmt.Sprintf("%v%v", ctv.TXT_MISSING_PARAMETER, ctv.FN_AWS_CLIENT_ID))
+		refresherPtr = nil
+		return
+	}
+	if refresherPtr.expiresAt, errorInfo = getTokenExpiry(tokens.ID); errorInfo.Error != nil {
+		refresherPtr = nil
+	}
+
+	return
+}
+
+// TokenExpiry - returns when the current Cognito ID token expires. The zero time is returned if the client isn't logged in.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) TokenExpiry() (expiresAt time.Time) {
+
+	if clientPtr.tokenRefresherPtr == nil {
+		return
+	}
+
+	return clientPtr.tokenRefresherPtr.getExpiry()
+}
+
+// getExpiry - returns the expiry of the current ID token.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (refresherPtr *tokenRefresher) getExpiry() time.Time {
+
+	refresherPtr.mutex.RLock()
+	defer refresherPtr.mutex.RUnlock()
+
+	return refresherPtr.expiresAt
+}
+
+// getLastErrorInfo - returns the error from the last renewal. It is empty if the last renewal succeeded.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (refresherPtr *tokenRefresher) getLastErrorInfo() pi.ErrorInfo {
+
+	refresherPtr.mutex.RLock()
+	defer refresherPtr.mutex.RUnlock()
+
+	return refresherPtr.lastErrorInfo
+}
+
+// getNATSConfig - returns the current NATS configuration. The refresher holds the only copy, so after a renewal it has the
+// latest credentials and TLS information.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (refresherPtr *tokenRefresher) getNATSConfig() ns.NATSConfiguration {
+
+	refresherPtr.mutex.RLock()
+	defer refresherPtr.mutex.RUnlock()
+
+	return refresherPtr.natsConfig
+}
+
+// refresh - renews the access and ID tokens using the refresh token, then reloads the AWS SSM parameters with the new ID token.
+// The request has a SECRET_HASH when the app client has a secret. If the NATS credentials or TLS information changed, the
+// temporary files are replaced so reconnects use the new values.
+//
+//	Customer Messages: None
+//	Errors: returned from InitiateAuth, getTokenExpiry, processAWSClientParameters, replaceTemporaryFiles
+//	Verifications: None
+func (refresherPtr *tokenRefresher) refresh() (errorInfo pi.ErrorInfo) {
+
+	var (
+		tAuthParameters        map[string]string
+		tCancel                context.CancelFunc
+		tCtx                   context.Context
+		tExpiresAt             time.Time
+		tInitiateAuthOutputPtr *awsCIP.InitiateAuthOutput
+		tNATSConfig            ns.NATSConfiguration
+		tTokens                awss.CognitoTokens
+	)
+
+	refresherPtr.mutex.RLock()
+	tTokens = refresherPtr.tokens
+	refresherPtr.mutex.RUnlock()
+
+	tAuthParameters = map[string]string{COGNITO_REFRESH_TOKEN: tTokens.Refresh}
+	if refresherPtr.clientSecret != ctv.VAL_EMPTY {
+		tAuthParameters[COGNITO_USERNAME] = refresherPtr.username
+		tAuthParameters[COGNITO_SECRET_HASH] = getSecretHash(refresherPtr.username, refresherPtr.clientId, refresherPtr.clientSecret)
+	}
+
+	tCtx, tCancel = context.WithTimeout(context.Background(), TOKEN_REFRESH_TIMEOUT)
+	defer tCancel()
+
+	if tInitiateAuthOutputPtr, errorInfo.Error = awsCIP.NewFromConfig(refresherPtr.awsSettings.BaseConfig).InitiateAuth(
+		tCtx, &awsCIP.InitiateAuthInput{
+			AuthFlow:       awsCT.AuthFlowTypeRefreshTokenAuth,
+			ClientId:       aws.String(refresherPtr.clientId),
+			AuthParameters: tAuthParameters,
+		},
+	); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v", ctv.TXT_SERVICE, ctv.TXT_AWS_COGNITO))
+		return
+	}
+	if tInitiateAuthOutputPtr.AuthenticationResult == nil {
+		errorInfo = pi.NewErrorInfo(pi.ErrServiceFailedCognito, fmt.Sprintf("%v%v", ctv.TXT_SERVICE, ctv.TXT_AWS_COGNITO))
+		return
+	}
+
+	tTokens.Access = aws.ToString(tInitiateAuthOutputPtr.AuthenticationResult.AccessToken)
+	tTokens.ID = aws.ToString(tInitiateAuthOutputPtr.AuthenticationResult.IdToken)
+	if tInitiateAuthOutputPtr.AuthenticationResult.RefreshToken != nil { // Cognito only returns a refresh token when it is rotated.
+		tTokens.Refresh = *tInitiateAuthOutputPtr.AuthenticationResult.RefreshToken
+	}
+	if tExpiresAt, errorInfo = getTokenExpiry(tTokens.ID); errorInfo.Error != nil {
+		return
+	}
+
+	refresherPtr.mutex.Lock()
+	refresherPtr.tokens = tTokens
+	refresherPtr.expiresAt = tExpiresAt
+	refresherPtr.mutex.Unlock()
+
+	// The SSM parameters are read with the ID token, so reload them in case the NATS credentials or TLS information were rotated.
+	if errorInfo = processAWSClientParameters(refresherPtr.awsSettings, tTokens.ID, refresherPtr.environment, &tNATSConfig); errorInfo.Error != nil {
+		return
+	}
+
+	refresherPtr.mutex.Lock()
+	defer refresherPtr.mutex.Unlock()
+
+	if refresherPtr.tempDirectory != ctv.VAL_EMPTY {
+		if errorInfo = replaceTemporaryFiles(
+			refresherPtr.tempDirectory,
+			tNATSConfig,
+			tNATSConfig.NATSToken != refresherPtr.natsConfig.NATSToken,
+			tNATSConfig.NATSTLSInfo.TLSCert != refresherPtr.natsConfig.NATSTLSInfo.TLSCert ||
+				tNATSConfig.NATSTLSInfo.TLSPrivateKey != refresherPtr.natsConfig.NATSTLSInfo.TLSPrivateKey ||
+				tNATSConfig.NATSTLSInfo.TLSCABundle != refresherPtr.natsConfig.NATSTLSInfo.TLSCABundle,
+		); errorInfo.Error != nil {
+			return
+		}
+	}
+	refresherPtr.natsConfig.NATSToken = tNATSConfig.NATSToken
+	refresherPtr.natsConfig.NATSTLSInfo.TLSCert = tNATSConfig.NATSTLSInfo.TLSCert
+	refresherPtr.natsConfig.NATSTLSInfo.TLSPrivateKey = tNATSConfig.NATSTLSInfo.TLSPrivateKey
+	refresherPtr.natsConfig.NATSTLSInfo.TLSCABundle = tNATSConfig.NATSTLSInfo.TLSCABundle
+
+	return
+}
+
+// run - renews the tokens TOKEN_REFRESH_LEAD_TIME before they expire until stop is called. Failed renewals are retried
+// every TOKEN_REFRESH_RETRY_WAIT.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (refresherPtr *tokenRefresher) run() {
+
+	var (
+		tTimerPtr *time.Timer
+		tWait     time.Duration
+	)
+
+	defer close(refresherPtr.doneChan)
+
+	for {
+		if tWait = time.Until(refresherPtr.getExpiry().Add(-TOKEN_REFRESH_LEAD_TIME)); tWait < TOKEN_REFRESH_RETRY_WAIT {
+			tWait = TOKEN_REFRESH_RETRY_WAIT
+		}
+		tTimerPtr = time.NewTimer(tWait)
+		select {
+		case <-refresherPtr.stopChan:
+			tTimerPtr.Stop()
+			return
+		case <-tTimerPtr.C:
+		}
+
+		errorInfo := refresherPtr.refresh()
+		refresherPtr.mutex.Lock()
+		refresherPtr.lastErrorInfo = errorInfo
+		refresherPtr.mutex.Unlock()
+		if errorInfo.Error != nil {
+			refresherPtr.loggerPtr.Warn(LOG_TOKEN_REFRESH_FAILED, errorInfoAttr(errorInfo))
+		} else {
+			refresherPtr.loggerPtr.Debug(LOG_TOKEN_REFRESHED, slog.Time(LOG_KEY_TOKEN_EXPIRY, refresherPtr.getExpiry()))
+		}
+	}
+}
+
+// start - launches the background renewal.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (refresherPtr *tokenRefresher) start() {
+
+	refresherPtr.mutex.Lock()
+	refresherPtr.started = true
+	refresherPtr.mutex.Unlock()
+
+	go refresherPtr.run()
+}
+
+// stop - ends the background renewal and waits for it to exit. Calling stop more than once, or before start, is safe.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (refresherPtr *tokenRefresher) stop() {
+
+	var (
+		tStarted bool
+	)
+
+	refresherPtr.stopOnce.Do(
+		func() {
+			close(refresherPtr.stopChan)
+		},
+	)
+
+	refresherPtr.mutex.RLock()
+	tStarted = refresherPtr.started
+	refresherPtr.mutex.RUnlock()
+
+	if tStarted {
+		<-refresherPtr.doneChan
+	}
+}
+
+// getCognitoClientId - returns the id of the app client the client logs in with. awss keeps it unexported, so it is read the
+// same way awss.Login reads it from the identity info.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func getCognitoClientId(identityInfo awss.CognitoIdentityInfo) string {
+
+	var (
+		tField = reflect.ValueOf(identityInfo).FieldByName(COGNITO_CLIENT_ID_FIELD)
+	)
+
+	if tField.IsValid() == false || tField.Kind() != reflect.String {
+		return ctv.VAL_EMPTY
+	}
+
+	return tField.String()
+}
+
+// getSecretHash - returns the Cognito SECRET_HASH: the base64 encoded HMAC-SHA256 of the username and app client id, keyed
+// with the app client secret.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func getSecretHash(username, clientId, clientSecret string) string {
+
+	var (
+		tMAC = hmac.New(sha256.New, []byte(clientSecret))
+	)
+
+	tMAC.Write([]byte(username + clientId))
+
+	return base64.StdEncoding.EncodeToString(tMAC.Sum(nil))
+}
+
+// getTokenExpiry - returns the expiration claim of the token. The token signature isn't verified, Cognito issued it
+// over TLS and the value is only used to schedule the renewal.
+//
+//	Customer Messages: None
+//	Errors: ErrJWTMissing, returned from ParseUnverified, GetExpirationTime
+//	Verifications: None
+func getTokenExpiry(token string) (expiresAt time.Time, errorInfo pi.ErrorInfo) {
+
+	var (
+		tClaims         = jwt.MapClaims{}
+		tExpirationTime *jwt.NumericDate
+	)
+
+	if _, _, errorInfo.Error = jwt.NewParser().ParseUnverified(token, tClaims); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v", ctv.TXT_TOKEN, ctv.FN_TOKEN))
+		return
+	}
+	if tExpirationTime, errorInfo.Error = tClaims.GetExpirationTime(); errorInfo.Error != nil {
+		errorInfo = pi.NewErrorInfo(errorInfo.Error, fmt.Sprintf("%v%v", ctv.TXT_TOKEN, ctv.FN_TOKEN))
+		return
+	}
+	if tExpirationTime == nil {
+		errorInfo = pi.NewErrorInfo(pi.ErrJWTMissing, fmt.Sprintf("%v%v", ctv.TXT_TOKEN, ctv.FN_TOKEN))
+		return
+	}
+
+	return tExpirationTime.Time, pi.ErrorInfo{}
+}
@@ -0,0 +1,74 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	jwts "github.com/sty-holdings/sty-shared/v2024/jwtServices"
+	ns "github.com/sty-holdings/sty-shared/v2024/natsSerices"
+)
+
+func TestGetSecretHash(tPtr *testing.T) {
+
+	if tGot := getSecretHash("USERNAME", "CLIENT_ID", "CLIENT_SECRET"); tGot != "QUAUxmaiulwjomzWbpcec20V2fwNb8YohW06XwO+B8Y=" {
+		tPtr.Errorf("got secret hash %v, want QUAUxmaiulwjomzWbpcec20V2fwNb8YohW06XwO+B8Y=", tGot)
+	}
+}
+
+func TestReplaceTemporaryFiles(tPtr *testing.T) {
+
+	var (
+		tEntries       []os.DirEntry
+		tError         error
+		tNATSConfig    = ns.NATSConfiguration{NATSToken: "NEW_TOKEN"}
+		tTempDirectory = tPtr.TempDir()
+	)
+
+	for _, filename := range []string{ns.CREDENTIAL_FILENAME, jwts.TLS_CERT_FILENAME} {
+		if tError = os.WriteFile(fmt.Sprintf("%v/%v", tTempDirectory, filename), []byte("OLD"), 0600); tError != nil {
+			tPtr.Fatal(tError)
+		}
+	}
+
+	if tErrorInfo := replaceTemporaryFiles(tTempDirectory, tNATSConfig, true, false); tErrorInfo.Error != nil {
+		tPtr.Fatalf("got error %v, want none", tErrorInfo.Error)
+	}
+	if tData, _ := os.ReadFile(fmt.Sprintf("%v/%v", tTempDirectory, ns.CREDENTIAL_FILENAME)); string(tData) != "NEW_TOKEN" {
+		tPtr.Errorf("got credentials %q, want NEW_TOKEN", tData)
+	}
+	if tData, _ := os.ReadFile(fmt.Sprintf("%v/%v", tTempDirectory, jwts.TLS_CERT_FILENAME)); string(tData) != "OLD" {
+		tPtr.Errorf("got certificate %q, want the unchanged file", tData)
+	}
+
+	// The TLS information is missing, so nothing is renamed and the staging directory is removed.
+	if tErrorInfo := replaceTemporaryFiles(tTempDirectory, tNATSConfig, false, true); tErrorInfo.Error == nil {
+		tPtr.Errorf("got no error, want the missing TLS information reported")
+	}
+	if tEntries, tError = os.ReadDir(tTempDirectory); tError != nil || len(tEntries) != 2 {
+		tPtr.Errorf("got %d entries and error %v, want only the two files", len(tEntries), tError)
+	}
+}
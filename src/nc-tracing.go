@@ -0,0 +1,154 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+//goland:noinspection ALL
+const (
+	ATTR_ATTEMPTS            = "nc.attempts"
+	ATTR_DESTINATION_NAME    = "messaging.destination.name"
+	ATTR_INSTANCE_NAME       = "nc.instance_name"
+	ATTR_MESSAGING_OPERATION = "messaging.operation"
+	ATTR_MESSAGING_SYSTEM    = "messaging.system"
+	ATTR_REPLY_SIZE          = "nc.reply.body.size"
+	ATTR_REQUEST_ID          = "nc.request_id"
+	ATTR_REQUEST_SIZE        = "messaging.message.body.size"
+	MESSAGING_OPERATION      = "request"
+	MESSAGING_SYSTEM         = "nats"
+	TRACER_NAME              = CLIENT_MODULE_PATH
+)
+
+// natsHeaderCarrier - lets the propagator read and write the W3C trace context in the NATS message header.
+type natsHeaderCarrier nats.Header
+
+// tracePropagator - W3C trace context, so the NATS Connect backend can continue the trace.
+var tracePropagator = propagation.TraceContext{}
+
+// Get - returns the first value for the key.
+func (carrier natsHeaderCarrier) Get(key string) string {
+
+	return nats.Header(carrier).Get(key)
+}
+
+// Keys - returns the header keys.
+func (carrier natsHeaderCarrier) Keys() (keys []string) {
+
+	for key := range carrier {
+		keys = append(keys, key)
+	}
+
+	return
+}
+
+// Set - replaces the values for the key.
+func (carrier natsHeaderCarrier) Set(key string, value string) {
+
+	nats.Header(carrier).Set(key, value)
+}
+
+// endSpan - records the outcome and ends the span. Failed requests record the error and set the span status to error.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func endSpan(span trace.Span, attempts int, errorInfo pi.ErrorInfo) {
+
+	span.SetAttributes(attribute.Int(ATTR_ATTEMPTS, attempts))
+	if errorInfo.Error != nil {
+		span.RecordError(errorInfo.Error)
+		span.SetStatus(codes.Error, errorInfo.Error.Error())
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	span.End()
+}
+
+// injectTraceContext - writes the W3C trace context for the span in the context into the message header.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func injectTraceContext(ctx context.Context, requestMsgPtr *nats.Msg) {
+
+	if requestMsgPtr.Header == nil {
+		requestMsgPtr.Header = nats.Header{}
+	}
+	tracePropagator.Inject(ctx, natsHeaderCarrier(requestMsgPtr.Header))
+}
+
+// newTracer - returns the tracer for the client. Without a tracer provider, a no-op tracer is used, which still passes on
+// the trace context of a span the caller started.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func newTracer(tracerProvider trace.TracerProvider) trace.Tracer {
+
+	if tracerProvider == nil {
+		tracerProvider = noop.NewTracerProvider()
+	}
+
+	return tracerProvider.Tracer(TRACER_NAME, trace.WithInstrumentationVersion(clientVersion))
+}
+
+// startSpan - starts a client span for the request on the subject.
+//
+//	Customer Messages: None
+//	Errors: None
+//	Verifications: None
+func (clientPtr *NCClient) startSpan(ctx context.Context, subject, requestId string) (context.Context, trace.Span) {
+
+	var (
+		tTracer = clientPtr.tracer
+	)
+
+	if tTracer == nil {
+		tTracer = newTracer(nil)
+	}
+
+	return tTracer.Start(
+		ctx,
+		subject,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String(ATTR_DESTINATION_NAME, subject),
+			attribute.String(ATTR_INSTANCE_NAME, clientPtr.natsService.InstanceName),
+			attribute.String(ATTR_MESSAGING_OPERATION, MESSAGING_OPERATION),
+			attribute.String(ATTR_MESSAGING_SYSTEM, MESSAGING_SYSTEM),
+			attribute.String(ATTR_REQUEST_ID, requestId),
+		),
+	)
+}
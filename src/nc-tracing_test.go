@@ -0,0 +1,114 @@
+// Package src
+// /*
+// Copyright 1/2024 STY Holdings Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the “Software”), to deal in
+// the Software without restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the
+// Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+// */
+package src
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	pi "github.com/sty-holdings/sty-shared/v2024/programInfo"
+)
+
+func TestSpanSuccess(tPtr *testing.T) {
+
+	var (
+		tRequestMsg nats.Msg
+		tSpan       sdktrace.ReadOnlySpan
+	)
+
+	tSpan, tRequestMsg = recordSpan(tPtr, 1, pi.ErrorInfo{})
+	if tSpan.Name() != "TEST_SUBJECT" || tSpan.SpanKind() != trace.SpanKindClient {
+		tPtr.Errorf("got span %v of kind %v, want TEST_SUBJECT of kind client", tSpan.Name(), tSpan.SpanKind())
+	}
+	if tSpan.Status().Code != codes.Ok || len(tSpan.Events()) != 0 {
+		tPtr.Errorf("got status %v with %d events, want Ok without events", tSpan.Status().Code, len(tSpan.Events()))
+	}
+	if hasAttribute(tSpan.Attributes(), attribute.Int(ATTR_ATTEMPTS, 1)) == false ||
+		hasAttribute(tSpan.Attributes(), attribute.String(ATTR_REQUEST_ID, "REQUEST_ID")) == false {
+		tPtr.Errorf("got attributes %v, want the attempts and request id", tSpan.Attributes())
+	}
+	if tRequestMsg.Header.Get("traceparent") != "00-"+tSpan.SpanContext().TraceID().String()+"-"+tSpan.SpanContext().SpanID().String()+"-01" {
+		tPtr.Errorf("got traceparent %v, want the span context", tRequestMsg.Header.Get("traceparent"))
+	}
+}
+
+func TestSpanError(tPtr *testing.T) {
+
+	var (
+		tSpan sdktrace.ReadOnlySpan
+	)
+
+	tSpan, _ = recordSpan(tPtr, 3, pi.NewErrorInfo(ErrRequestTimeout, "test"))
+	if tSpan.Status().Code != codes.Error {
+		tPtr.Errorf("got status %v, want Error", tSpan.Status().Code)
+	}
+	if len(tSpan.Events()) != 1 || tSpan.Events()[0].Name != "exception" {
+		tPtr.Errorf("got events %v, want one exception", tSpan.Events())
+	}
+	if hasAttribute(tSpan.Attributes(), attribute.Int(ATTR_ATTEMPTS, 3)) == false {
+		tPtr.Errorf("got attributes %v, want 3 attempts", tSpan.Attributes())
+	}
+}
+
+// hasAttribute - returns true when the attributes include the key with the value.
+func hasAttribute(attributes []attribute.KeyValue, want attribute.KeyValue) bool {
+
+	for _, keyValue := range attributes {
+		if keyValue == want {
+			return true
+		}
+	}
+
+	return false
+}
+
+// recordSpan - starts and ends a request span with the outcome, returning the recorded span and the request message the
+// trace context was injected into.
+func recordSpan(tPtr *testing.T, attempts int, errorInfo pi.ErrorInfo) (span sdktrace.ReadOnlySpan, requestMsg nats.Msg) {
+
+	var (
+		tClient      NCClient
+		tCtx         context.Context
+		tRecorderPtr = tracetest.NewSpanRecorder()
+		tSpan        trace.Span
+	)
+
+	tClient.tracer = newTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tRecorderPtr)))
+	tCtx, tSpan = tClient.startSpan(context.Background(), "TEST_SUBJECT", "REQUEST_ID")
+	injectTraceContext(tCtx, &requestMsg)
+	endSpan(tSpan, attempts, errorInfo)
+
+	if len(tRecorderPtr.Ended()) != 1 {
+		tPtr.Fatalf("got %d ended spans, want 1", len(tRecorderPtr.Ended()))
+	}
+
+	return tRecorderPtr.Ended()[0], requestMsg
+}